@@ -0,0 +1,7 @@
+// Package postgres registers the PostgreSQL driver (lib/pq) for connections with driver postgres.
+// Import it for its side effect in binaries that connect to PostgreSQL:
+//
+//	import _ "multi-db/driver/postgres"
+package postgres
+
+import _ "github.com/lib/pq"
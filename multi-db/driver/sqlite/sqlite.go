@@ -0,0 +1,7 @@
+// Package sqlite registers the SQLite driver (go-sqlite3, which needs cgo) for connections with driver sqlite3.
+// Import it for its side effect in binaries that connect to SQLite:
+//
+//	import _ "multi-db/driver/sqlite"
+package sqlite
+
+import _ "github.com/mattn/go-sqlite3"
@@ -0,0 +1,334 @@
+// Package gen writes model structs and their query constructors from the live schema of a registered database
+package gen
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"multi-db/mysql"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Options selects the models Generate writes
+type Options struct {
+	// DB is the registered database to read, empty for the default
+	DB string
+	// Package is the package name of the generated files
+	Package string
+	// Tables limits the generated tables, nil generates every table
+	Tables []string
+	// DBExpr is the Go expression passed to New() for the database name - by default the quoted name,
+	// or its constant (Database1, Database2) when generating package mysql
+	DBExpr string
+}
+
+// File is a generated source file
+type File struct {
+	Name string
+	// Model is the model name, e.g. AdsTag for AdsTagModel and AdsTagQuery()
+	Model  string
+	Source []byte
+}
+
+// dbConsts are the database name constants of package mysql
+var dbConsts = map[string]string{
+	mysql.Database1: "Database1",
+	mysql.Database2: "Database2",
+}
+
+// Run runs the gen command:
+//
+//	multidb gen [-config config.yaml] [-db bg_dsp4] [-pkg mysql] [-out mysql] [-tables ads_tags,test] [-dbexpr Database1]
+//
+// One file is written per table into -out, or printed when -out is -. A model already declared in -out,
+// generated or written by hand under another file name, is replaced in the file declaring it (see Write).
+func Run(args []string) error {
+	flags := flag.NewFlagSet("gen", flag.ContinueOnError)
+	config := flags.String("config", "", "config file, see mysql.LoadConfig")
+	db := flags.String("db", "", "registered database to read (default: the config default)")
+	pkg := flags.String("pkg", "mysql", "package name of the generated files")
+	out := flags.String("out", "mysql", "directory to write the files to, - prints them")
+	tables := flags.String("tables", "", "comma separated tables to generate (default: every table)")
+	dbExpr := flags.String("dbexpr", "", "Go expression for the database name passed to New()")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	err = mysql.ConnectConfig(*config)
+	if err != nil {
+		return err
+	}
+	defer mysql.CloseAll()
+
+	opts := Options{DB: *db, Package: *pkg, DBExpr: *dbExpr}
+	if *tables != "" {
+		opts.Tables = strings.Split(*tables, ",")
+	}
+	files, err := Generate(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+	if *out == "-" {
+		for _, f := range files {
+			fmt.Printf("// %s\n%s\n", f.Name, f.Source)
+		}
+		return nil
+	}
+	paths, err := Write(*out, files)
+	for _, path := range paths {
+		fmt.Println("wrote", path)
+	}
+	return err
+}
+
+// Generate describes the tables of opts.DB and renders a model file for each
+func Generate(ctx context.Context, opts Options) ([]File, error) {
+	c, err := mysql.Lookup(opts.DB)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Package == "" {
+		opts.Package = "mysql"
+	}
+	if opts.DBExpr == "" {
+		opts.DBExpr = strconv.Quote(c.Name)
+		if name, ok := dbConsts[c.Name]; ok && opts.Package == "mysql" {
+			opts.DBExpr = name
+		}
+	}
+	tables := opts.Tables
+	if len(tables) == 0 {
+		tables, err = mysql.TablesContext(ctx, c.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var files []File
+	for _, name := range tables {
+		t, err := mysql.DescribeContext(ctx, c.Name, strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		src, err := Model(t, c.Name, opts.Package, opts.DBExpr)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, File{Name: fileName(t.Name), Model: modelName(t.Name), Source: src})
+	}
+	return files, nil
+}
+
+// declarations maps the top level types and functions declared in the Go files of dir to their file name
+func declarations(dir string) (map[string]string, error) {
+	decls := map[string]string{}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing %s: %s", path, err)
+		}
+		name := filepath.Base(path)
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil {
+					decls[d.Name.Name] = name
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					if ts, ok := spec.(*ast.TypeSpec); ok {
+						decls[ts.Name.Name] = name
+					}
+				}
+			}
+		}
+	}
+	return decls, nil
+}
+
+// target returns the file name f is written to: the file already declaring its model, else f.Name
+func target(f File, decls map[string]string) (string, error) {
+	model, hasModel := decls[f.Model+"Model"]
+	query, hasQuery := decls[f.Model+"Query"]
+	switch {
+	case hasModel && hasQuery && model != query:
+		return "", fmt.Errorf("Error writing %s: %sModel is declared in %s and %sQuery in %s", f.Name, f.Model, model, f.Model, query)
+	case hasModel:
+		return model, nil
+	case hasQuery:
+		return query, nil
+	}
+	return f.Name, nil
+}
+
+// Model renders the model struct and XxxQuery() constructor of table t in database dbName
+func Model(t *mysql.Table, dbName string, pkg string, dbExpr string) ([]byte, error) {
+	name := modelName(t.Name)
+	imports := map[string]bool{}
+	var fields strings.Builder
+	for _, col := range t.Columns {
+		typ, imp := goType(col)
+		if imp != "" {
+			imports[imp] = true
+		}
+		fmt.Fprintf(&fields, "\t%s %s `builder:%q`\n", fieldName(col.Name), typ, col.Name)
+	}
+	prefix := ""
+	if pkg != "mysql" {
+		imports["multi-db/mysql"] = true
+		prefix = "mysql."
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Code generated by multidb gen from %s.%s. DO NOT EDIT.\n\n", dbName, t.Name)
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	var imps []string
+	for _, imp := range []string{"time", "multi-db/mysql"} {
+		if imports[imp] {
+			imps = append(imps, strconv.Quote(imp))
+		}
+	}
+	switch len(imps) {
+	case 0:
+	case 1:
+		fmt.Fprintf(&b, "import %s\n\n", imps[0])
+	default:
+		fmt.Fprintf(&b, "import (\n\t%s\n)\n\n", strings.Join(imps, "\n\t"))
+	}
+	fmt.Fprintf(&b, "// %sModel is a row of %s\n", name, t.Name)
+	fmt.Fprintf(&b, "type %sModel struct {\n%s}\n\n", name, fields.String())
+	fmt.Fprintf(&b, "// %sQuery builds a Query on %s\n", name, t.Name)
+	fmt.Fprintf(&b, "func %sQuery() *%sQuery {\n", name, prefix)
+	fmt.Fprintf(&b, "\treturn %sNew(%q, %q, %s)\n}\n", prefix, t.Name, primaryKey(t), dbExpr)
+
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return nil, fmt.Errorf("Error formatting model for %s: %s", t.Name, err)
+	}
+	return src, nil
+}
+
+// goType maps a column to the Go type of its field and the import it needs.
+// Nullable columns are pointers, so NULL reads as nil and nil writes NULL.
+func goType(col mysql.Column) (string, string) {
+	typ, imp := baseType(col)
+	if col.Nullable && typ != "[]byte" {
+		typ = "*" + typ
+	}
+	return typ, imp
+}
+
+func baseType(col mysql.Column) (string, string) {
+	full := strings.ToLower(col.Type)
+	unsigned := strings.Contains(full, "unsigned")
+	switch col.DataType {
+	case "tinyint":
+		// MySQL booleans
+		if strings.HasPrefix(full, "tinyint(1)") {
+			return "bool", ""
+		}
+		fallthrough
+	case "smallint", "mediumint", "int", "integer", "int2", "int4", "serial", "smallserial":
+		if unsigned {
+			return "uint", ""
+		}
+		return "int", ""
+	case "bigint", "int8", "bigserial":
+		if unsigned {
+			return "uint64", ""
+		}
+		return "int64", ""
+	case "float", "double", "double precision", "real", "float4", "float8":
+		return "float64", ""
+	case "bool", "boolean":
+		return "bool", ""
+	case "date", "datetime", "timestamp", "timestamp without time zone", "timestamp with time zone":
+		return "time.Time", "time"
+	case "binary", "varbinary", "blob", "tinyblob", "mediumblob", "longblob", "bytea", "bit":
+		return "[]byte", ""
+	}
+	// Text, decimals (kept exact), enums, json, times of day and anything unknown
+	return "string", ""
+}
+
+// primaryKey returns the key column passed to New(): the first primary key column, else id, else the first column
+func primaryKey(t *mysql.Table) string {
+	if len(t.PrimaryKey) > 0 {
+		return t.PrimaryKey[0]
+	}
+	if t.Column("id") != nil {
+		return "id"
+	}
+	return t.Columns[0].Name
+}
+
+// modelName turns a table name like ads_tags into AdsTag
+func modelName(table string) string {
+	words := splitName(table)
+	if n := len(words); n > 0 {
+		words[n-1] = singular(words[n-1])
+	}
+	return camel(words)
+}
+
+// fieldName turns a column name like ad_id into AdId
+func fieldName(col string) string {
+	return camel(splitName(col))
+}
+
+// fileName turns a table name like ads_tags into ads_tag.go
+func fileName(table string) string {
+	words := splitName(table)
+	if n := len(words); n > 0 {
+		words[n-1] = singular(words[n-1])
+	}
+	return strings.ToLower(strings.Join(words, "_")) + ".go"
+}
+
+// splitName splits a name on every character that is not a letter or digit
+func splitName(name string) []string {
+	return strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func camel(words []string) string {
+	var b strings.Builder
+	for _, w := range words {
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	s := b.String()
+	if s == "" || !unicode.IsLetter(rune(s[0])) {
+		// Identifiers cannot start with a digit
+		s = "X" + s
+	}
+	return s
+}
+
+// singular drops the plural ending of a word: tags -> tag, categories -> category
+func singular(w string) string {
+	lower := strings.ToLower(w)
+	switch {
+	case strings.HasSuffix(lower, "ies") && len(w) > 3:
+		return w[:len(w)-3] + "y"
+	case strings.HasSuffix(lower, "ss") || strings.HasSuffix(lower, "us") || strings.HasSuffix(lower, "is"):
+		return w
+	case strings.HasSuffix(lower, "s") && len(w) > 1:
+		return w[:len(w)-1]
+	}
+	return w
+}
@@ -0,0 +1,127 @@
+package gen
+
+import (
+	"context"
+	"flag"
+	"multi-db/mysql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "multi-db/driver/sqlite"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func strPtr(s string) *string {
+	return &s
+}
+
+// adsTags is a MySQL table using every type mapping
+var adsTags = &mysql.Table{
+	Name: "ads_tags",
+	Columns: []mysql.Column{
+		{Name: "id", Type: "bigint unsigned", DataType: "bigint", AutoIncrement: true},
+		{Name: "ad_id", Type: "int", DataType: "int"},
+		{Name: "content_tag", Type: "varchar(64)", DataType: "varchar", Nullable: true},
+		{Name: "active", Type: "tinyint(1)", DataType: "tinyint", Default: strPtr("1")},
+		{Name: "score", Type: "double", DataType: "double", Nullable: true},
+		{Name: "payload", Type: "blob", DataType: "blob", Nullable: true},
+		{Name: "created_at", Type: "datetime", DataType: "datetime"},
+	},
+	PrimaryKey: []string{"id"},
+}
+
+func TestModelGolden(t *testing.T) {
+	tests := []struct {
+		golden string
+		pkg    string
+		dbExpr string
+	}{
+		{"ads_tag.golden", "mysql", "Database1"},
+		{"ads_tag_models.golden", "models", `"bg_dsp4"`},
+	}
+	for _, tt := range tests {
+		src, err := Model(adsTags, "bg_dsp4", tt.pkg, tt.dbExpr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join("testdata", tt.golden)
+		if *update {
+			err = os.WriteFile(path, src, 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(src) != string(want) {
+			t.Errorf("Model for package %s differs from %s:\n%s", tt.pkg, path, src)
+		}
+	}
+}
+
+func TestTarget(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"test-bg.go": "package mysql\n\ntype TestBgModel struct{}\n\nfunc TestBgQuery() *Query { return nil }\n",
+		"split.go":   "package mysql\n\ntype SplitModel struct{}\n",
+		"split_q.go": "package mysql\n\nfunc SplitQuery() *Query { return nil }\n",
+	}
+	for name, src := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	decls, err := declarations(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name, err := target(File{Name: "test_bg.go", Model: "TestBg"}, decls)
+	if err != nil || name != "test-bg.go" {
+		t.Errorf("target for TestBg = %q, %v, want the existing test-bg.go", name, err)
+	}
+	name, err = target(File{Name: "ads_tag.go", Model: "AdsTag"}, decls)
+	if err != nil || name != "ads_tag.go" {
+		t.Errorf("target for a new model = %q, %v, want ads_tag.go", name, err)
+	}
+	_, err = target(File{Name: "split.go", Model: "Split"}, decls)
+	if err == nil {
+		t.Error("target for a model split across files returned no error")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	err := mysql.ConnectionConfig{Name: "gen_test", Driver: "sqlite3"}.Register()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mysql.Close("gen_test")
+	db, err := mysql.DB("gen_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec("CREATE TABLE user_categories (id INTEGER PRIMARY KEY, name TEXT NOT NULL, note TEXT)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := Generate(context.Background(), Options{DB: "gen_test", Package: "models"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Name != "user_category.go" || files[0].Model != "UserCategory" {
+		t.Fatalf("Generate = %v, want user_category.go", files)
+	}
+	// Ignore the alignment of fields
+	src := strings.Join(strings.Fields(string(files[0].Source)), " ")
+	for _, want := range []string{"type UserCategoryModel struct", "Name string `builder:\"name\"`", "Note *string", `mysql.New("user_categories", "id", "gen_test")`} {
+		if !strings.Contains(src, want) {
+			t.Errorf("generated source lacks %s:\n%s", want, src)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package gen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Write writes files into dir and returns the paths written. A model goes to the file already declaring it,
+// else to f.Name. Models sharing a file are written together, and only their XxxModel and XxxQuery
+// declarations are replaced: the rest of the file, methods on the models included, is kept.
+func Write(dir string, files []File) ([]string, error) {
+	decls, err := declarations(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	targets := map[string][]File{}
+	models := map[string]string{}
+	for _, f := range files {
+		if other, ok := models[f.Model]; ok {
+			return nil, fmt.Errorf("Error writing %s: model %s is also generated as %s", f.Name, f.Model, other)
+		}
+		models[f.Model] = f.Name
+		name, err := target(f, decls)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := targets[name]; !ok {
+			names = append(names, name)
+		}
+		targets[name] = append(targets[name], f)
+	}
+	var paths []string
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		src, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return paths, fmt.Errorf("Error reading %s: %s", path, err)
+		}
+		src, err = merge(path, src, targets[name])
+		if err != nil {
+			return paths, err
+		}
+		err = os.WriteFile(path, src, 0644)
+		if err != nil {
+			return paths, fmt.Errorf("Error writing %s: %s", path, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// merge replaces the declarations of the models of files in src, the source of the file at path.
+// A new file, or one declaring nothing but the model of a single file, is that file's source.
+func merge(path string, src []byte, files []File) ([]byte, error) {
+	if len(src) == 0 {
+		if len(files) == 1 {
+			return files[0].Source, nil
+		}
+		src, files = files[0].Source, files[1:]
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing %s: %s", path, err)
+	}
+	generated := map[string]bool{}
+	for _, gf := range files {
+		generated[gf.Model+"Model"] = true
+		generated[gf.Model+"Query"] = true
+	}
+	var cuts []edit
+	// The cut each model's generated declarations take the place of
+	at := map[string]int{}
+	others := 0
+	for _, decl := range f.Decls {
+		names := declNames(decl)
+		n := 0
+		for _, name := range names {
+			if generated[name] {
+				n++
+			}
+		}
+		switch {
+		case n == 0:
+			if d, ok := decl.(*ast.GenDecl); !ok || d.Tok != token.IMPORT {
+				others++
+			}
+		case n < len(names):
+			return nil, fmt.Errorf("Error in %s: %s is declared in a group with other types", path, strings.Join(names, ", "))
+		default:
+			model := strings.TrimSuffix(strings.TrimSuffix(names[0], "Model"), "Query")
+			if _, ok := at[model]; !ok {
+				at[model] = len(cuts)
+			}
+			cuts = append(cuts, edit{start: offset(fset, declStart(decl)), end: offset(fset, decl.End())})
+		}
+	}
+	if others == 0 && len(files) == 1 {
+		// Nothing written by hand to keep
+		return files[0].Source, nil
+	}
+
+	var imports []*ast.ImportSpec
+	tail := ""
+	for _, gf := range files {
+		g, err := parser.ParseFile(token.NewFileSet(), gf.Name, gf.Source, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing generated %s: %s", gf.Name, err)
+		}
+		if g.Name.Name != f.Name.Name {
+			return nil, fmt.Errorf("Error writing %s into %s: package %s, not %s", gf.Model, path, g.Name.Name, f.Name.Name)
+		}
+		imports = append(imports, g.Imports...)
+		var decls []string
+		for _, decl := range g.Decls {
+			if d, ok := decl.(*ast.GenDecl); ok && d.Tok == token.IMPORT {
+				continue
+			}
+			decls = append(decls, string(gf.Source[declStart(decl)-g.FileStart:decl.End()-g.FileStart]))
+		}
+		if i, ok := at[gf.Model]; ok {
+			cuts[i].text = strings.Join(decls, "\n\n")
+		} else {
+			tail += "\n\n" + strings.Join(decls, "\n\n")
+		}
+	}
+	src = apply(src, cuts)
+	src = append(src, tail+"\n"...)
+	src, err = fixImports(path, src, imports)
+	if err != nil {
+		return nil, err
+	}
+	out, err := format.Source(src)
+	if err != nil {
+		return nil, fmt.Errorf("Error formatting %s: %s", path, err)
+	}
+	return out, nil
+}
+
+// fixImports adds the imports of generated code that src uses and lacks, and drops those it no longer uses
+func fixImports(path string, src []byte, generated []*ast.ImportSpec) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing %s: %s", path, err)
+	}
+	used := map[string]bool{}
+	ast.Inspect(f, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				used[id.Name] = true
+			}
+		}
+		return true
+	})
+	imported := map[string]bool{}
+	var edits []edit
+	var first *ast.GenDecl
+	for _, decl := range f.Decls {
+		d, ok := decl.(*ast.GenDecl)
+		if !ok || d.Tok != token.IMPORT {
+			continue
+		}
+		var unused []*ast.ImportSpec
+		for _, spec := range d.Specs {
+			spec := spec.(*ast.ImportSpec)
+			p, _ := strconv.Unquote(spec.Path.Value)
+			imported[p] = true
+			if name, known := importName(spec); known && !used[name] {
+				unused = append(unused, spec)
+			}
+		}
+		if len(unused) == len(d.Specs) {
+			edits = append(edits, edit{start: offset(fset, declStart(d)), end: offset(fset, d.End())})
+			continue
+		}
+		if first == nil {
+			first = d
+		}
+		for _, spec := range unused {
+			start := spec.Pos()
+			if spec.Doc != nil {
+				start = spec.Doc.Pos()
+			}
+			edits = append(edits, line(src, offset(fset, start), offset(fset, spec.End())))
+		}
+	}
+	var missing []string
+	for _, spec := range generated {
+		p, _ := strconv.Unquote(spec.Path.Value)
+		name, _ := importName(spec)
+		if used[name] && !imported[p] {
+			imported[p] = true
+			missing = append(missing, spec.Path.Value)
+		}
+	}
+	sort.Strings(missing)
+	switch {
+	case len(missing) == 0:
+	case first != nil && first.Lparen.IsValid():
+		edits = append(edits, edit{start: offset(fset, first.Lparen) + 1, end: offset(fset, first.Lparen) + 1,
+			text: "\n\t" + strings.Join(missing, "\n\t")})
+	default:
+		at := offset(fset, f.Name.End())
+		edits = append(edits, edit{start: at, end: at, text: "\n\nimport (\n\t" + strings.Join(missing, "\n\t") + "\n)"})
+	}
+	return apply(src, edits), nil
+}
+
+// importName returns the name a file refers to an import by, and false when it cannot be told from the
+// import alone (blank and dot imports, paths not ending in an identifier like gopkg.in/yaml.v3)
+func importName(spec *ast.ImportSpec) (string, bool) {
+	if spec.Name != nil {
+		return spec.Name.Name, spec.Name.Name != "_" && spec.Name.Name != "."
+	}
+	p, _ := strconv.Unquote(spec.Path.Value)
+	name := path.Base(p)
+	return name, token.IsIdentifier(name)
+}
+
+// declNames returns the names of the types and plain functions decl declares, nil for anything else
+func declNames(decl ast.Decl) []string {
+	var names []string
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if d.Recv == nil {
+			names = append(names, d.Name.Name)
+		}
+	case *ast.GenDecl:
+		for _, spec := range d.Specs {
+			if ts, ok := spec.(*ast.TypeSpec); ok {
+				names = append(names, ts.Name.Name)
+			}
+		}
+	}
+	return names
+}
+
+// declStart returns the start of decl, with its doc comment
+func declStart(decl ast.Decl) token.Pos {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if d.Doc != nil {
+			return d.Doc.Pos()
+		}
+	case *ast.GenDecl:
+		if d.Doc != nil {
+			return d.Doc.Pos()
+		}
+	}
+	return decl.Pos()
+}
+
+func offset(fset *token.FileSet, pos token.Pos) int {
+	return fset.Position(pos).Offset
+}
+
+// line widens the edit removing src[start:end] to the whole lines it is on
+func line(src []byte, start int, end int) edit {
+	for start > 0 && (src[start-1] == ' ' || src[start-1] == '\t') {
+		start--
+	}
+	if end < len(src) && src[end] == '\n' {
+		end++
+	}
+	return edit{start: start, end: end}
+}
+
+// edit replaces src[start:end] with text
+type edit struct {
+	start, end int
+	text       string
+}
+
+// apply applies non-overlapping edits to src
+func apply(src []byte, edits []edit) []byte {
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].start > edits[j].start
+	})
+	out := append([]byte(nil), src...)
+	for _, e := range edits {
+		out = append(out[:e.start], append([]byte(e.text), out[e.end:]...)...)
+	}
+	return out
+}
@@ -0,0 +1,119 @@
+package gen
+
+import (
+	"multi-db/mysql"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// models holds two models written by hand with a method and the imports they use
+const models = `package models
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"multi-db/mysql"
+)
+
+// AdsTagModel is written by hand
+type AdsTagModel struct {
+	Id  int             ` + "`builder:\"id\"`" + `
+	Raw json.RawMessage ` + "`builder:\"payload\"`" + `
+}
+
+func AdsTagQuery() *mysql.Query {
+	return mysql.New("ads_tags", "id", "bg_dsp4")
+}
+
+// UserModel is a row of users
+type UserModel struct {
+	Id int ` + "`builder:\"id\"`" + `
+}
+
+// UserQuery builds a Query on users
+func UserQuery() *mysql.Query {
+	return mysql.New("users", "id", "bg_dsp4")
+}
+
+// String describes the user
+func (m UserModel) String() string {
+	return fmt.Sprintf("user %d", m.Id)
+}
+`
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "models.go"), []byte(models), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(dir, "tag.go"), []byte("package models\n\ntype TagModel struct{}\n\nfunc TagQuery() {}\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := &mysql.Table{
+		Name: "users",
+		Columns: []mysql.Column{
+			{Name: "id", Type: "bigint", DataType: "bigint"},
+			{Name: "name", Type: "varchar(64)", DataType: "varchar", Nullable: true},
+		},
+		PrimaryKey: []string{"id"},
+	}
+	tags := &mysql.Table{Name: "tags", Columns: []mysql.Column{{Name: "id", Type: "int", DataType: "int"}}}
+	orders := &mysql.Table{Name: "orders", Columns: []mysql.Column{{Name: "id", Type: "int", DataType: "int"}}}
+	var files []File
+	for _, table := range []*mysql.Table{adsTags, users, tags, orders} {
+		src, err := Model(table, "bg_dsp4", "models", `"bg_dsp4"`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, File{Name: fileName(table.Name), Model: modelName(table.Name), Source: src})
+	}
+
+	paths, err := Write(dir, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(dir, "models.go"), filepath.Join(dir, "tag.go"), filepath.Join(dir, "order.go")}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("Write wrote %v, want %v", paths, want)
+	}
+	// The models are replaced in place, keeping the method and dropping the import only the old model used
+	got, err := os.ReadFile(filepath.Join(dir, "models.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := filepath.Join("testdata", "models.golden")
+	if *update {
+		err = os.WriteFile(golden, got, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	} else if wantSrc, err := os.ReadFile(golden); err != nil || string(got) != string(wantSrc) {
+		t.Errorf("models.go differs from %s (%v):\n%s", golden, err, got)
+	}
+	// A file declaring nothing else is the generated file
+	for _, i := range []int{2, 3} {
+		got, err := os.ReadFile(paths[i-1])
+		if err != nil || string(got) != string(files[i].Source) {
+			t.Errorf("%s = %s, %v, want the generated source", paths[i-1], got, err)
+		}
+	}
+	// Running again changes nothing
+	_, err = Write(dir, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := os.ReadFile(filepath.Join(dir, "models.go"))
+	if err != nil || string(again) != string(got) {
+		t.Errorf("models.go after a second run = %s, %v, want it unchanged", again, err)
+	}
+
+	_, err = Write(dir, append(files, files[0]))
+	if err == nil {
+		t.Error("Write of a model generated twice returned no error")
+	}
+}
@@ -1,31 +1,43 @@
 package handle
 
 import (
+	"context"
 	"fmt"
 	"multi-db/mysql"
 )
 
 func InsertMultiDb() {
-	mysql.ConnectMysqlDb1()
-	mysql.ConnectMysqlDb2()
-	// Get data from db1
-	queryDb1 := mysql.AdsTagQuery().Select("*")
-	rsDb1, err1 := queryDb1.Results()
-	if err1 != nil {
-		fmt.Println(err1.Error())
+	InsertMultiDbContext(context.Background())
+}
+
+// InsertMultiDbContext copies ads_tags from db1 into ads_tag_copy on db2, stopping when ctx is done
+func InsertMultiDbContext(ctx context.Context) {
+	err := mysql.ConnectConfig("")
+	if err != nil {
+		fmt.Println("MYSQL connect error: " + err.Error())
+		return
 	}
-	for _, v := range rsDb1 {
-		data := mysql.AdsTagQuery().SetData(v, mysql.AdsTagModel{}).(mysql.AdsTagModel)
-		// insert to db 2
-		_, err2 := mysql.AdsTagCopyQuery().InsertObject(mysql.AdsTagCopyModel{
-			AdId:       data.AdId,
-			ContentTag: data.ContentTag,
-		})
-		if err2 != nil {
-			fmt.Println(err1.Error())
-		}
+	defer mysql.CloseAll()
+	// Copy inside one XA transaction, so the target is never left partially filled
+	report, err := mysql.Copy(ctx, mysql.CopySpec{
+		SourceDB:    mysql.Database1,
+		SourceTable: "ads_tags",
+		SourcePK:    "id",
+		TargetDB:    mysql.Database2,
+		TargetTable: "ads_tag_copy",
+		TargetPK:    "id",
+		// Copy the id too, so a re-run updates the rows already copied instead of duplicating them
+		Columns: map[string]string{
+			"id":          "id",
+			"ad_id":       "ad_id",
+			"content_tag": "content_tag",
+		},
+		ChunkSize: 5000,
+		Mode:      mysql.ModeUpsert,
+		Atomic:    true,
+	})
+	if err != nil {
+		fmt.Println(err.Error())
 	}
-
-	mysql.CloseDb1()
-	mysql.CloseDb2()
+	fmt.Println("copy ads_tags:", report)
 }
@@ -1,7 +1,21 @@
 package main
 
-import "multi-db/handle"
+import (
+	"fmt"
+	"multi-db/gen"
+	"multi-db/handle"
+	"os"
+)
 
 func main()  {
+	// multidb gen writes the models from the schema, see gen.Run
+	if len(os.Args) > 1 && os.Args[1] == "gen" {
+		err := gen.Run(os.Args[2:])
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
 	handle.InsertMultiDb()
 }
@@ -0,0 +1,6 @@
+//go:build postgres
+
+package main
+
+// Build with -tags postgres to connect to PostgreSQL, e.g. for multidb gen
+import _ "multi-db/driver/postgres"
@@ -0,0 +1,6 @@
+//go:build sqlite
+
+package main
+
+// Build with -tags sqlite to connect to SQLite, e.g. for multidb gen
+import _ "multi-db/driver/sqlite"
@@ -0,0 +1,340 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultBatchSize is the number of rows per multi-row INSERT unless set with Query.BatchSize
+var DefaultBatchSize = 500
+
+// defaultMaxPacket is assumed when max_allowed_packet cannot be read (the MySQL 5.7 default)
+const defaultMaxPacket = 4 << 20
+
+// noPacketLimit is the statement size limit used on dialects without max_allowed_packet
+const noPacketLimit = 1 << 30
+
+// WriteMode selects the INSERT statement variant
+type WriteMode int
+
+const (
+	// ModeInsert is a plain INSERT, failing on duplicate keys
+	ModeInsert WriteMode = iota
+	// ModeIgnore is INSERT IGNORE (ON CONFLICT DO NOTHING on PostgreSQL, INSERT OR IGNORE on SQLite),
+	// skipping rows with duplicate keys
+	ModeIgnore
+	// ModeReplace is REPLACE INTO, deleting the existing row before inserting (on PostgreSQL the row is updated)
+	ModeReplace
+	// ModeUpsert is INSERT ... ON DUPLICATE KEY UPDATE (ON CONFLICT DO UPDATE on PostgreSQL and SQLite), see Query.Upsert
+	ModeUpsert
+)
+
+// WriteResult reports the rows written by insert statements
+type WriteResult struct {
+	// Rows sent by the statements that succeeded
+	Rows int64
+	// Affected rows reported by the database - REPLACE counts 2 for a replaced row, upserts 2 for an updated row
+	Affected int64
+	// InsertIDs holds the first insert id of each statement sent
+	InsertIDs []int64
+
+	// Inserted, Replaced and Skipped split Rows by outcome, worked out from Affected for ModeInsert,
+	// ModeIgnore and ModeReplace (upserts cannot tell updated from unchanged rows, so only set Affected)
+	Inserted int64
+	Replaced int64
+	Skipped  int64
+}
+
+func (r *WriteResult) add(o WriteResult) {
+	r.Rows += o.Rows
+	r.Affected += o.Affected
+	r.InsertIDs = append(r.InsertIDs, o.InsertIDs...)
+	r.Inserted += o.Inserted
+	r.Replaced += o.Replaced
+	r.Skipped += o.Skipped
+}
+
+// count splits the rows of a statement by outcome from its affected rows
+func (r *WriteResult) count(mode WriteMode) {
+	switch mode {
+	case ModeInsert:
+		r.Inserted = r.Affected
+	case ModeIgnore:
+		r.Inserted = r.Affected
+		r.Skipped = r.Rows - r.Affected
+	case ModeReplace:
+		// Each replaced row is one delete plus one insert
+		r.Replaced = r.Affected - r.Rows
+		if r.Replaced > r.Rows {
+			// A row conflicting on several unique keys deletes several rows
+			r.Replaced = r.Rows
+		}
+		r.Inserted = r.Rows - r.Replaced
+	}
+}
+
+// BatchSize sets the number of rows per multi-row INSERT
+func (q *Query) BatchSize(n int) *Query {
+	q.batchSize = n
+	return q
+}
+
+// InsertBatch inserts rows with multi-row INSERT statements, split by the batch size and by max_allowed_packet.
+// Every row must have the same columns.
+func (q *Query) InsertBatch(rows []map[string]interface{}) (WriteResult, error) {
+	return q.InsertBatchContext(context.Background(), rows)
+}
+
+// InsertBatchContext is InsertBatch bound to ctx
+func (q *Query) InsertBatchContext(ctx context.Context, rows []map[string]interface{}) (WriteResult, error) {
+	return q.writeBatch(ctx, rows, ModeInsert, nil)
+}
+
+// writeBatch sends rows with multi-row statements of the given mode, updateCols are the upsert columns
+func (q *Query) writeBatch(ctx context.Context, rows []map[string]interface{}, mode WriteMode, updateCols []string) (WriteResult, error) {
+	var result WriteResult
+	if len(rows) == 0 {
+		return result, nil
+	}
+	cols := sortedParamKeys(rows[0])
+	for i, row := range rows {
+		if len(row) != len(cols) {
+			return result, fmt.Errorf("Error in batch row %d: columns differ from row 0", i)
+		}
+		for _, col := range cols {
+			if _, ok := row[col]; !ok {
+				return result, fmt.Errorf("Error in batch row %d: missing column %s", i, col)
+			}
+		}
+	}
+	size := q.batchSize
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+	if max := q.dialect().MaxArgs(); size*len(cols) > max {
+		size = max / len(cols)
+	}
+	// Leave room for the statement and protocol overhead
+	limit := q.maxPacket(ctx) * 9 / 10
+	if mode == ModeUpsert {
+		updateCols = q.upsertColumns(cols, updateCols)
+	}
+	clause := q.dialect().OnConflict(mode, q.primaryKey, cols, updateCols)
+
+	start := 0
+	bytes := int64(0)
+	for i, row := range rows {
+		rowBytes := rowSize(cols, row)
+		if i > start && (i-start >= size || bytes+rowBytes > limit) {
+			r, err := q.insertChunk(ctx, mode, cols, rows[start:i], clause)
+			result.add(r)
+			if err != nil {
+				return result, err
+			}
+			start = i
+			bytes = 0
+		}
+		bytes += rowBytes
+	}
+	r, err := q.insertChunk(ctx, mode, cols, rows[start:], clause)
+	result.add(r)
+	return result, err
+}
+
+// InsertObjects inserts a slice of structs (or pointers to structs) with multi-row INSERT statements
+func (q *Query) InsertObjects(objects interface{}) (WriteResult, error) {
+	return q.InsertObjectsContext(context.Background(), objects)
+}
+
+// InsertObjectsContext is InsertObjects bound to ctx
+func (q *Query) InsertObjectsContext(ctx context.Context, objects interface{}) (WriteResult, error) {
+	rows, err := paramsFromObjects(objects)
+	if err != nil {
+		return WriteResult{}, err
+	}
+	return q.InsertBatchContext(ctx, rows)
+}
+
+// paramsFromObjects builds insert params for each struct of a slice
+func paramsFromObjects(objects interface{}) ([]map[string]interface{}, error) {
+	val := reflect.ValueOf(objects)
+	if val.Kind() != reflect.Slice && val.Kind() != reflect.Array {
+		return nil, fmt.Errorf("Error reading objects: %T is not a slice", objects)
+	}
+	rows := make([]map[string]interface{}, val.Len())
+	for i := range rows {
+		params, err := paramsFromObject(val.Index(i).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("Error in object %d: %s", i, err)
+		}
+		rows[i] = params
+	}
+	return rows, nil
+}
+
+func (q *Query) insertChunk(ctx context.Context, mode WriteMode, cols []string, rows []map[string]interface{}, suffix string) (WriteResult, error) {
+	var result WriteResult
+	sql := q.formatBatchInsertSQL(mode, cols, len(rows)) + suffix
+	args := make([]interface{}, 0, len(cols)*len(rows))
+	for _, row := range rows {
+		for _, col := range cols {
+			args = append(args, row[col])
+		}
+	}
+	if Debug {
+		fmt.Printf("INSERT SQL:%s %v\n", sql, args)
+	}
+	if q.dialect().Returning() {
+		return q.insertChunkReturning(ctx, mode, rows, sql, args)
+	}
+	rs, err := exec(ctx, q.executor(), sql, args...)
+	if err != nil {
+		return result, err
+	}
+	result.Affected, err = rs.RowsAffected()
+	if err != nil {
+		return result, err
+	}
+	result.Rows = int64(len(rows))
+	result.count(mode)
+	id, err := rs.LastInsertId()
+	if err == nil {
+		result.InsertIDs = append(result.InsertIDs, id)
+	}
+	return result, nil
+}
+
+// insertChunkReturning sends a chunk with RETURNING, counting the returned rows as affected.
+// Replaced rows are told apart by their xmax on PostgreSQL, and counted before the insert on SQLite.
+func (q *Query) insertChunkReturning(ctx context.Context, mode WriteMode, chunk []map[string]interface{}, sql string, args []interface{}) (WriteResult, error) {
+	var result WriteResult
+	d := q.dialect()
+	returning := q.pk()
+	updates := mode == ModeReplace && d.Name() == "postgres"
+	if updates {
+		// xmax is only set on the rows the conflict updated
+		returning += ", xmax <> 0"
+	}
+	if mode == ModeReplace && d.Name() == "sqlite" {
+		n, err := q.existingKeys(ctx, chunk)
+		if err != nil {
+			return result, err
+		}
+		result.Replaced = n
+	}
+	rows, err := querySql(ctx, q.executor(), fmt.Sprintf("%s RETURNING %s", sql, returning), args...)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id interface{}
+		var updated bool
+		if updates {
+			err = rows.Scan(&id, &updated)
+		} else {
+			err = rows.Scan(&id)
+		}
+		if err != nil {
+			return WriteResult{}, err
+		}
+		if result.Affected == 0 {
+			if n, ok := id.(int64); ok {
+				result.InsertIDs = append(result.InsertIDs, n)
+			}
+		}
+		if updated {
+			result.Replaced++
+		}
+		result.Affected++
+	}
+	err = rows.Err()
+	if err != nil {
+		return WriteResult{}, err
+	}
+	result.Rows = int64(len(chunk))
+	if mode == ModeReplace {
+		result.Inserted = result.Rows - result.Replaced
+	} else {
+		result.count(mode)
+	}
+	return result, nil
+}
+
+// existingKeys counts the rows of chunk whose primary key is already in the table
+func (q *Query) existingKeys(ctx context.Context, chunk []map[string]interface{}) (int64, error) {
+	var keys []interface{}
+	for _, row := range chunk {
+		if v, ok := row[q.primaryKey]; ok && v != nil {
+			keys = append(keys, v)
+		}
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	existing := &Query{tableName: q.tableName, primaryKey: q.primaryKey, dbName: q.dbName, db: q.db, d: q.d}
+	return existing.WhereIn(q.pk(), keys).CountContext(ctx)
+}
+
+func (q *Query) formatBatchInsertSQL(mode WriteMode, cols []string, n int) string {
+	var quoted, vals []string
+	for _, col := range cols {
+		quoted = append(quoted, q.quote(col))
+	}
+	d := q.dialect()
+	for i := 0; i < n; i++ {
+		placeholders := make([]string, len(cols))
+		for j := range cols {
+			placeholders[j] = d.Placeholder(i*len(cols) + j + 1)
+		}
+		vals = append(vals, fmt.Sprintf("(%s)", strings.Join(placeholders, ",")))
+	}
+	return fmt.Sprintf("%s %s (%s) VALUES%s", d.InsertVerb(mode), q.tableName, strings.Join(quoted, ","), strings.Join(vals, ","))
+}
+
+// maxPacket returns max_allowed_packet for the query's database, read once per connection.
+// Other servers have no packet limit worth splitting on, so get noPacketLimit.
+func (q *Query) maxPacket(ctx context.Context) int64 {
+	if q.dialect().Name() != "mysql" {
+		return noPacketLimit
+	}
+	c, err := Lookup(q.dbName)
+	if err != nil {
+		return defaultMaxPacket
+	}
+	if n := atomic.LoadInt64(&c.maxPacket); n > 0 {
+		return n
+	}
+	n := int64(defaultMaxPacket)
+	rows, err := querySql(ctx, q.executor(), "SELECT @@max_allowed_packet")
+	if err == nil {
+		if rows.Next() && rows.Scan(&n) != nil {
+			n = defaultMaxPacket
+		}
+		rows.Close()
+	}
+	atomic.StoreInt64(&c.maxPacket, n)
+	return n
+}
+
+// rowSize estimates the bytes a row takes in an execute packet
+func rowSize(cols []string, row map[string]interface{}) int64 {
+	size := int64(0)
+	for _, col := range cols {
+		switch v := row[col].(type) {
+		case string:
+			size += int64(len(v)) + 9
+		case []byte:
+			size += int64(len(v)) + 9
+		case time.Time:
+			size += 12
+		default:
+			size += 9
+		}
+	}
+	return size
+}
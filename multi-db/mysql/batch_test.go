@@ -0,0 +1,115 @@
+package mysql
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// maxArgs is SQLite with a lower cap on placeholders per statement
+type maxArgs struct {
+	Dialect
+	n int
+}
+
+func (d maxArgs) MaxArgs() int {
+	return d.n
+}
+
+// packetLimited is SQLite under the mysql name, so batches are split by max_allowed_packet
+type packetLimited struct {
+	Dialect
+}
+
+func (packetLimited) Name() string {
+	return "mysql"
+}
+
+func batchRows(n int) []map[string]interface{} {
+	rows := make([]map[string]interface{}, n)
+	for i := range rows {
+		rows[i] = map[string]interface{}{"ad_id": i, "tag": strings.Repeat("x", 50)}
+	}
+	return rows
+}
+
+func TestInsertBatchSplit(t *testing.T) {
+	tests := []struct {
+		name       string
+		batchSize  int
+		dialect    Dialect
+		maxPacket  int64
+		statements int
+	}{
+		{"one statement", 0, SQLite, 0, 1},
+		{"batch size", 2, SQLite, 0, 3},
+		{"max args", 0, maxArgs{SQLite, 5}, 0, 3},
+		// A row takes 68 bytes, 2 rows fit in 90% of 160
+		{"max packet", 0, packetLimited{SQLite}, 160, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openSQLite(t, "CREATE TABLE tags (id INTEGER PRIMARY KEY AUTOINCREMENT, ad_id INTEGER, tag TEXT)")
+			if tt.maxPacket > 0 {
+				c, err := Lookup(db)
+				if err != nil {
+					t.Fatal(err)
+				}
+				atomic.StoreInt64(&c.maxPacket, tt.maxPacket)
+			}
+			q := New("tags", "id", db).BatchSize(tt.batchSize)
+			q.d = tt.dialect
+			result, err := q.InsertBatch(batchRows(5))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Rows != 5 || result.Inserted != 5 || len(result.InsertIDs) != tt.statements {
+				t.Errorf("InsertBatch = %+v, want 5 rows in %d statements", result, tt.statements)
+			}
+			if n := count(t, db, "tags"); n != 5 {
+				t.Errorf("Count = %d, want 5", n)
+			}
+		})
+	}
+}
+
+func TestInsertBatchColumns(t *testing.T) {
+	db := openSQLite(t, "CREATE TABLE tags (id INTEGER PRIMARY KEY AUTOINCREMENT, ad_id INTEGER, tag TEXT)")
+	tests := map[string][]map[string]interface{}{
+		"columns differ": {{"ad_id": 1, "tag": "a"}, {"ad_id": 2}},
+		"missing column": {{"ad_id": 1, "tag": "a"}, {"ad_id": 2, "name": "b"}},
+	}
+	for want, rows := range tests {
+		_, err := New("tags", "id", db).InsertBatch(rows)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("InsertBatch error = %v, want %s", err, want)
+		}
+	}
+	if n := count(t, db, "tags"); n != 0 {
+		t.Errorf("Count = %d, want nothing inserted", n)
+	}
+}
+
+func TestInsertObjects(t *testing.T) {
+	db := openSQLite(t, "CREATE TABLE tags (id INTEGER PRIMARY KEY AUTOINCREMENT, ad_id INTEGER, tag TEXT)")
+	type tag struct {
+		Id   int64  `builder:"id,omit"`
+		AdId int    `builder:"ad_id"`
+		Tag  string `builder:"tag"`
+	}
+	result, err := New("tags", "id", db).InsertObjects([]tag{{AdId: 1, Tag: "a"}, {AdId: 2, Tag: "b"}})
+	if err != nil || result.Rows != 2 {
+		t.Errorf("InsertObjects = %+v, %v, want 2 rows", result, err)
+	}
+	result, err = New("tags", "id", db).InsertObjects([]*tag{{AdId: 3, Tag: "c"}})
+	if err != nil || result.Rows != 1 {
+		t.Errorf("InsertObjects of pointers = %+v, %v, want 1 row", result, err)
+	}
+	tags, err := All[tag](New("tags", "id", db).Order("id"))
+	if err != nil || len(tags) != 3 || tags[2] != (tag{3, 3, "c"}) {
+		t.Errorf("tags = %v, %v", tags, err)
+	}
+	if _, err = New("tags", "id", db).InsertObjects(tag{}); err == nil {
+		t.Error("InsertObjects of a struct returned no error")
+	}
+}
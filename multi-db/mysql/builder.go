@@ -1,9 +1,9 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"github.com/mitchellh/mapstructure"
 	"reflect"
 	"regexp"
 	"sort"
@@ -12,7 +12,6 @@ import (
 )
 
 var Debug bool
-var DbConnection *sql.DB
 
 func init() {
 	Debug = false // default to false
@@ -27,6 +26,14 @@ type Query struct {
 	tableName  string
 	primaryKey string
 
+	// Connection - registered database name, set with New(), and the transaction the query is bound to
+	// with UseTx or UseXA - without one the query runs on the pool registered for dbName, see executor()
+	dbName string
+	db     executor
+
+	// Dialect of the connection, see dialect()
+	d Dialect
+
 	// SQL - Private fields used to store sql before building sql query
 	sql    string
 	sel    []string
@@ -42,78 +49,111 @@ type Query struct {
 
 	// Extra args to be substituted in the *where* clause
 	args []interface{}
+
+	// Last WHERE group and the clause before it, so OrWhereIn can extend the group
+	whereHead string
+	whereLast Condition
+	// Whether the where groups filter rows, for safe mode
+	whereFilter whereFilter
+
+	// Args to be substituted in the *having* clause
+	havingArgs []interface{}
+
+	// Subqueries in the select and from clauses - their args, and the alias of FromSub()
+	selArgs   []interface{}
+	fromArgs  []interface{}
+	fromAlias string
+
+	// Compound queries, set with Union(), UnionAll() and With()
+	unions    []union
+	ctes      []cte
+	recursive bool
+
+	// Rows per multi-row INSERT, set with BatchSize()
+	batchSize int
+
+	// Read the inserted row back into the object on InsertObject, set with ReturnInserted()
+	returnInserted bool
+
+	// Guards for UpdateAll and DeleteAll, set with AllowFullTable() and MaxAffected()
+	allowFullTable bool
+	maxAffected    int64
 }
 
-// New builds a new Query, given the table and primary key
+// New builds a new Query, given the table and primary key, on the registered database db (or the default database)
 func New(t string, pk string, db ...string) *Query {
-	switch db[0] {
-	case Database1:
-		if mysqlConDb1 == nil {
-			return nil
-		}
-		DbConnection = mysqlConDb1
-		break
-	case Database2:
-		if mysqlConDb2 == nil {
-			return nil
-		}
-		DbConnection = mysqlConDb2
-		break
-	default:
-		if mysqlConDb1 == nil {
-			return nil
-		}
-		DbConnection = mysqlConDb1
+	name := ""
+	if len(db) > 0 {
+		name = db[0]
+	}
+	c, err := Lookup(name)
+	if err != nil {
+		return nil
 	}
 	q := &Query{
 		tableName:  t,
 		primaryKey: pk,
+		dbName:     c.Name,
+		d:          c.Dialect,
 	}
 
 	return q
 }
+
+// executor returns the transaction the query is bound to, else the pool registered for its database when
+// the query runs, so queries built before Register replaces that pool use the new one
+func (q *Query) executor() executor {
+	if q.db != nil {
+		return q.db
+	}
+	c, err := Lookup(q.dbName)
+	if err != nil {
+		return nil
+	}
+	return c.DB
+}
+
+// clone returns a copy of the query that can be extended without changing q
+func (q *Query) clone() *Query {
+	c := *q
+	c.sel = append([]string(nil), q.sel...)
+	c.args = append([]interface{}(nil), q.args...)
+	c.havingArgs = append([]interface{}(nil), q.havingArgs...)
+	c.selArgs = append([]interface{}(nil), q.selArgs...)
+	c.fromArgs = append([]interface{}(nil), q.fromArgs...)
+	c.unions = append([]union(nil), q.unions...)
+	c.ctes = append([]cte(nil), q.ctes...)
+	return &c
+}
+
+// SetData copies a result row into a copy of object (a struct with builder tags) and returns it
+// Columns that do not fit their field are left zero.
+//
+// Deprecated: use All, First or ScanResult, which report mapping errors
 func (q *Query) SetData(data map[string]interface{}, object interface{}) interface{} {
-	result := make(map[string]interface{})
-	st := reflect.TypeOf(object)
-	num := st.NumField()
-	// for 1
-	for i := 0; i < num; i++ {
-		item := st.Field(i)
-		// for in data
-		for v, _ := range data {
-			// check theo tag
-			builderName := item.Tag.Get("builder")
-			builder := strings.Replace(builderName, ",omit", "", -1)
-			builder = strings.Replace(builder, ",", "", -1)
-			if builder == v {
-				// switch
-				switch item.Type.Kind() {
-				case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-					format := fmt.Sprintf("%v", data[v])
-					result[item.Name], _ = strconv.Atoi(format)
-				case reflect.String:
-					result[item.Name] = fmt.Sprintf("%v", data[v])
-				case reflect.Float64:
-					format := fmt.Sprintf("%v", data[v])
-					result[item.Name], _ = strconv.ParseFloat(format, 64)
-				default:
-					result[item.Name] = data[v]
-				}
-			}
+	val := reflect.New(reflect.TypeOf(object))
+	val.Elem().Set(reflect.ValueOf(object))
+	for _, f := range builderFields(val.Elem().Type()) {
+		if v, ok := data[f.name]; ok {
+			assign(val.Elem().FieldByIndex(f.index), v)
 		}
 	}
-	mapstructure.Decode(result, &object)
-	return object
+	return val.Elem().Interface()
 }
 
 // Insert inserts a record in the database
 func (q *Query) Insert(params map[string]interface{}) (int64, error) {
+	return q.InsertContext(context.Background(), params)
+}
+
+// InsertContext is Insert bound to ctx
+func (q *Query) InsertContext(ctx context.Context, params map[string]interface{}) (int64, error) {
 	// Insert and retrieve ID in one step from db
 	sql := q.formatInsertSQL(params)
 	if Debug {
 		fmt.Printf("INSERT SQL:%s %v\n", sql, valuesFromParams(params))
 	}
-	id, err := Insert(sql, valuesFromParams(params)...)
+	id, err := q.insert(ctx, sql, valuesFromParams(params)...)
 	if err != nil {
 		return 0, err
 	}
@@ -121,35 +161,30 @@ func (q *Query) Insert(params map[string]interface{}) (int64, error) {
 }
 
 // Insert a object in the database
+// On PostgreSQL and SQLite a zero primary key is not inserted, so the database assigns it as MySQL does for 0.
 func (q *Query) InsertObject(object interface{}) (int64, error) {
-	var params = make(map[string]interface{})
-	////--- Extract Value without specifying Type
-	val := reflect.Indirect(reflect.ValueOf(object))
-	for i := 0; i < val.Type().NumField(); i++ {
-		// create map param
-		builder := val.Type().Field(i).Tag.Get("builder")
-		if builder != "" && !strings.Contains(builder, "omit") {
-			// switch
-			switch val.Field(i).Type().Kind() {
-			case reflect.Int, reflect.Int16, reflect.Int32, reflect.Int64:
-				params[val.Type().Field(i).Tag.Get("builder")] = val.Field(i).Int()
-			case reflect.String:
-				params[val.Type().Field(i).Tag.Get("builder")] = val.Field(i).String()
-			case reflect.Float32:
-				params[val.Type().Field(i).Tag.Get("builder")] = val.Field(i).Float()
-			case reflect.Float64:
-				params[val.Type().Field(i).Tag.Get("builder")] = val.Field(i).Float()
-			default:
-			}
+	return q.InsertObjectContext(context.Background(), object)
+}
 
-		}
+// InsertObjectContext is InsertObject bound to ctx
+func (q *Query) InsertObjectContext(ctx context.Context, object interface{}) (int64, error) {
+	params, err := paramsFromObject(object)
+	if err != nil {
+		return 0, err
+	}
+	// MySQL assigns a key for 0 itself (unless NO_AUTO_VALUE_ON_ZERO is set), the others would store the 0
+	if v, ok := params[q.primaryKey]; ok && isZero(v) && q.dialect().Name() != "mysql" {
+		delete(params, q.primaryKey)
+	}
+	if q.returnInserted {
+		return q.insertObjectReturning(ctx, object, params)
 	}
 	// Insert and retrieve ID in one step from db
 	sql := q.formatInsertSQL(params)
 	if Debug {
 		fmt.Printf("INSERT SQL:%s %v\n", sql, valuesFromParams(params))
 	}
-	id, err := Insert(sql, valuesFromParams(params)...)
+	id, err := q.insert(ctx, sql, valuesFromParams(params)...)
 	if err != nil {
 		return 0, err
 	}
@@ -160,53 +195,102 @@ func (q *Query) InsertObject(object interface{}) (int64, error) {
 func (q *Query) formatInsertSQL(params map[string]interface{}) string {
 	var cols, vals []string
 	for i, k := range sortedParamKeys(params) {
-		cols = append(cols, QuoteField(k))
-		vals = append(vals, Placeholder(i+1))
+		cols = append(cols, q.quote(k))
+		vals = append(vals, q.dialect().Placeholder(i+1))
 	}
 	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES(%s)", q.tableName, strings.Join(cols, ","), strings.Join(vals, ","))
 	return query
 }
 
+// insert runs an insert statement and returns the id of the new row, read with RETURNING on dialects without LastInsertId
+func (q *Query) insert(ctx context.Context, sql string, args ...interface{}) (int64, error) {
+	d := q.dialect()
+	if d.Returning() {
+		sql = fmt.Sprintf("%s RETURNING %s", sql, q.pk())
+	}
+	return insert(ctx, q.executor(), d, sql, args...)
+}
+
 // Update one model specified in this query - the column names MUST be verified in the model
 func (q *Query) Update(params map[string]interface{}) (int64, error) {
-	return q.UpdateAll(params)
+	return q.UpdateAllContext(context.Background(), params)
+}
+
+// UpdateContext is Update bound to ctx
+func (q *Query) UpdateContext(ctx context.Context, params map[string]interface{}) (int64, error) {
+	return q.UpdateAllContext(ctx, params)
 }
 
 // UpdateAll updates all models specified in this relation
+// In SafeMode a relation without WHERE (or LIMIT on MySQL) returns a FullTableError, see AllowFullTable
 func (q *Query) UpdateAll(params map[string]interface{}) (int64, error) {
+	return q.UpdateAllContext(context.Background(), params)
+}
+
+// UpdateAllContext is UpdateAll bound to ctx
+func (q *Query) UpdateAllContext(ctx context.Context, params map[string]interface{}) (int64, error) {
+	err := q.checkLimit("UPDATE")
+	if err != nil {
+		return 0, err
+	}
+	err = q.checkFullTable("UPDATE")
+	if err != nil {
+		return 0, err
+	}
 	// Create sql for update from ALL params
-	q.UpdateSql(fmt.Sprintf("UPDATE %s SET %s", q.table(), querySQL(params)))
+	q.UpdateSql(fmt.Sprintf("UPDATE %s SET %s", q.table(), q.querySQL(params)))
 	q.args = append(valuesFromParams(params), q.args...)
 	if Debug {
 		fmt.Printf("UPDATE SQL:%s\n%v\n", q.QueryString(), valuesFromParams(params))
 	}
-	rs, err := q.Result()
-	id, err := rs.RowsAffected()
-	return id, err
+	return q.execGuarded(ctx, "UPDATE")
 }
 
 // DeleteAll delets *all* models specified in this relation
+// In SafeMode a relation without WHERE (or LIMIT on MySQL) returns a FullTableError, see AllowFullTable
 func (q *Query) DeleteAll() error {
-	q.Select(fmt.Sprintf("DELETE FROM %s", q.table()))
+	return q.DeleteAllContext(context.Background())
+}
+
+// DeleteAllContext is DeleteAll bound to ctx
+func (q *Query) DeleteAllContext(ctx context.Context) error {
+	err := q.checkLimit("DELETE")
+	if err != nil {
+		return err
+	}
+	err = q.checkFullTable("DELETE")
+	if err != nil {
+		return err
+	}
+	q.UpdateSql(fmt.Sprintf("DELETE FROM %s", q.table()))
 	if Debug {
 		fmt.Printf("DELETE SQL:%s <= %v\n", q.QueryString(), q.args)
 	}
 	// Execute
-	_, err := q.Result()
+	_, err = q.execGuarded(ctx, "DELETE")
 	return err
 }
 
 // Count fetches a count of model objects (executes SQL).
+// A query with Union or UnionAll counts the rows of the whole compound query.
 func (q *Query) Count() (int64, error) {
+	return q.CountContext(context.Background())
+}
+
+// CountContext is Count bound to ctx
+func (q *Query) CountContext(ctx context.Context) (int64, error) {
+	if len(q.unions) > 0 {
+		return q.countCompound(ctx)
+	}
 	// Store the previous select and set
-	s := q.sel
-	countSelect := fmt.Sprintf("SELECT COUNT(%s) FROM %s", q.pk(), q.table())
+	s, sArgs := q.sel, q.selArgs
+	countSelect := fmt.Sprintf("COUNT(%s)", q.pk())
 	q.Select(countSelect)
 	o := strings.Replace(q.order, "ORDER BY ", "", 1)
 	q.order = ""
 	// Fetch count from db for our sql with count select and no order set
 	var count int64
-	rows, err := q.Rows()
+	rows, err := q.RowsContext(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("Error querying database for count: %s\nQuery:%s", err, q.QueryString())
 	}
@@ -217,34 +301,72 @@ func (q *Query) Count() (int64, error) {
 			return 0, err
 		}
 	}
+	err = rows.Err()
 
 	// Reset select after getting count query
 	q.Select(s...)
+	q.selArgs = sArgs
 	q.Order(o)
 	q.reset()
 
 	return count, err
 }
 
+// countCompound counts the rows of a compound query as a derived table, since every
+// select of the compound would need its own COUNT
+func (q *Query) countCompound(ctx context.Context) (int64, error) {
+	sql, args := q.subquery()
+	d := q.dialect()
+	query := replacePlaceholders(d, fmt.Sprintf("SELECT COUNT(*) FROM (%s) AS %s", sql, d.QuoteField("t")), len(args))
+	rows, err := querySql(ctx, q.executor(), query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("Error querying database for count: %s\nQuery:%s", err, query)
+	}
+	defer rows.Close()
+	var count int64
+	for rows.Next() {
+		err = rows.Scan(&count)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return count, rows.Err()
+}
+
 // Result executes the query against the database, returning sql.Result, and error (no rows)
 // (Executes SQL)
 func (q *Query) Result() (sql.Result, error) {
-	results, err := Exec(q.QueryString(), q.args...)
+	return q.ResultContext(context.Background())
+}
+
+// ResultContext is Result bound to ctx
+func (q *Query) ResultContext(ctx context.Context) (sql.Result, error) {
+	results, err := exec(ctx, q.executor(), q.QueryString(), q.queryArgs()...)
 	return results, err
 }
 
 // Rows executes the query against the database, and return the sql rows result for this query
 func (q *Query) Rows() (*sql.Rows, error) {
-	results, err := QuerySql(q.QueryString(), q.args...)
+	return q.RowsContext(context.Background())
+}
+
+// RowsContext is Rows bound to ctx
+func (q *Query) RowsContext(ctx context.Context) (*sql.Rows, error) {
+	results, err := querySql(ctx, q.executor(), q.QueryString(), q.queryArgs()...)
 	return results, err
 }
 
 // FirstResult executes the SQL and returrns the first result
 func (q *Query) FirstResult() (Result, error) {
+	return q.FirstResultContext(context.Background())
+}
+
+// FirstResultContext is FirstResult bound to ctx
+func (q *Query) FirstResultContext(ctx context.Context) (Result, error) {
 	// Set a limit on the query
 	q.Limit(1)
 	// Fetch all results (1)
-	results, err := q.Results()
+	results, err := q.ResultsContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -257,36 +379,50 @@ func (q *Query) FirstResult() (Result, error) {
 
 // Results returns an array of results
 func (q *Query) Results() ([]Result, error) {
+	return q.ResultsContext(context.Background())
+}
+
+// ResultsContext is Results bound to ctx - cancelling ctx aborts the query and the row scan
+func (q *Query) ResultsContext(ctx context.Context) ([]Result, error) {
 	// Make an empty result set map
 	var results []Result
-	rows, err := q.Rows()
+	rows, err := q.RowsContext(ctx)
 	if err != nil {
-		return results, fmt.Errorf("Error querying database for rows: %s\nQUERY:%s", err, q)
+		return results, fmt.Errorf("Error querying database for rows: %s\nQUERY:%s", err, q.QueryString())
 	}
 	defer rows.Close()
 	cols, err := rows.Columns()
 	if err != nil {
-		return results, fmt.Errorf("Error fetching columns: %s\nQUERY:%s\nCOLS:%s", err, q, cols)
+		return results, fmt.Errorf("Error fetching columns: %s\nQUERY:%s\nCOLS:%s", err, q.QueryString(), cols)
 	}
 	for rows.Next() {
 		result, err := ScanRow(cols, rows)
 		if err != nil {
-			return results, fmt.Errorf("Error fetching row: %s\nQUERY:%s\nCOLS:%s", err, q, cols)
+			return results, fmt.Errorf("Error fetching row: %s\nQUERY:%s\nCOLS:%s", err, q.QueryString(), cols)
 		}
 		results = append(results, result)
 	}
+	err = rows.Err()
+	if err != nil {
+		return results, fmt.Errorf("Error fetching rows: %s\nQUERY:%s", err, q.QueryString())
+	}
 	return results, nil
 }
 
 func (q *Query) ResultsSimple() (*sql.Rows, []string, error) {
-	rows, err := q.Rows()
+	return q.ResultsSimpleContext(context.Background())
+}
+
+// ResultsSimpleContext is ResultsSimple bound to ctx
+func (q *Query) ResultsSimpleContext(ctx context.Context) (*sql.Rows, []string, error) {
+	rows, err := q.RowsContext(ctx)
 	cols := make([]string, 0)
 	if err != nil {
-		return rows, cols, fmt.Errorf("Error querying database for rows: %s\nQUERY:%s", err, q)
+		return rows, cols, fmt.Errorf("Error querying database for rows: %s\nQUERY:%s", err, q.QueryString())
 	}
 	cols, err = rows.Columns()
 	if err != nil {
-		return rows, cols, fmt.Errorf("Error fetching columns: %s\nQUERY:%s\nCOLS:%s", err, q, cols)
+		return rows, cols, fmt.Errorf("Error fetching columns: %s\nQUERY:%s\nCOLS:%s", err, q.QueryString(), cols)
 	}
 	return rows, cols, nil
 }
@@ -294,81 +430,83 @@ func (q *Query) ResultsSimple() (*sql.Rows, []string, error) {
 // QueryString builds a query string to use for results
 func (q *Query) QueryString() string {
 	if q.sql == "" {
-		selectSlice := make([]string, len(q.sel))
-		for i, v := range q.sel {
-			selectSlice[i] = fmt.Sprintf("%s", trim(v))
-		}
-		selectSql := ""
-		if len(q.sel) <= 0 {
-			selectSql = fmt.Sprintf("SELECT %s.* FROM %s", q.table(), q.table())
-		} else {
-			selectSql = fmt.Sprintf("SELECT %s FROM %s", strings.Join(selectSlice, ","), q.table())
-		}
-		if len(q.update) > 0 {
-			selectSql = q.update
-		}
-		q.sql = fmt.Sprintf("%s %s %s %s %s %s %s %s", selectSql, q.join, q.where, q.group, q.having, q.order, q.limit, q.offset)
-		q.sql = strings.TrimRight(q.sql, " ")
-		q.sql = strings.Replace(q.sql, "  ", " ", -1)
-		q.sql = strings.Replace(q.sql, "   ", " ", -1)
+		q.sql = q.buildSQL()
 		// Replace ? with whatever placeholder db prefers
 		q.replaceArgPlaceholders()
 
 		q.sql = q.sql + ";"
-		fmt.Println("sql..", q.sql)
+		if Debug {
+			fmt.Println("sql..", q.sql)
+		}
 	}
 
 	return q.sql
 }
 
+// buildSQL renders the statement with ? placeholders and no terminator, so it can be embedded as a subquery
+func (q *Query) buildSQL() string {
+	selectSlice := make([]string, len(q.sel))
+	for i, v := range q.sel {
+		selectSlice[i] = fmt.Sprintf("%s", trim(v))
+	}
+	selectSql := ""
+	if len(q.sel) <= 0 {
+		selectSql = fmt.Sprintf("SELECT %s.* FROM %s", q.star(), q.table())
+	} else {
+		selectSql = fmt.Sprintf("SELECT %s FROM %s", strings.Join(selectSlice, ","), q.table())
+	}
+	order, limit := q.order, q.dialect().LimitOffset(q.limit, q.offset)
+	if len(q.update) > 0 {
+		selectSql = q.update
+		if !q.dialect().UpdateLimit() {
+			order, limit = "", ""
+		}
+	}
+	var parts []string
+	for _, part := range []string{q.withSQL(), selectSql, q.join, q.where, q.group, q.having, q.unionSQL(), order, limit} {
+		part = strings.TrimSpace(part)
+		if len(part) > 0 {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// checkLimit returns an error for an UPDATE or DELETE with an order or limit the dialect cannot render
+func (q *Query) checkLimit(op string) error {
+	if len(q.offset) > 0 {
+		return fmt.Errorf("Error in %s on %s: OFFSET is not allowed", op, q.tableName)
+	}
+	if (len(q.order) > 0 || len(q.limit) > 0) && !q.dialect().UpdateLimit() {
+		return fmt.Errorf("Error in %s on %s: %s has no ORDER BY or LIMIT on %s", op, q.tableName, q.dialect().Name(), op)
+	}
+	return nil
+}
+
 // Limit sets the sql LIMIT with an int
 func (q *Query) Limit(limit int) *Query {
-	q.limit = fmt.Sprintf("LIMIT %d", limit)
+	q.limit = strconv.Itoa(limit)
 	q.reset()
 	return q
 }
 
 // Offset sets the sql OFFSET with an int
 func (q *Query) Offset(offset int) *Query {
-	q.offset = fmt.Sprintf("OFFSET %d", offset)
+	q.offset = strconv.Itoa(offset)
 	q.reset()
 	return q
 }
 
 // Where defines a WHERE clause on SQL - Additional calls add WHERE () AND () clauses
+// Values are always sent as bound parameters, either with placeholders:
+//	q.Where("ad_id = ? AND content_tag = ?", 5, "sport")
+//	q.Where("ad_id IN (?)", []int{1, 2, 3})
+// as column, operator and value:
+//	q.Where("ad_id", "=", 5)
+// or as a Condition:
+//	q.Where(mysql.Or(mysql.Eq("ad_id", 5), mysql.Like("content_tag", "sport%")))
 func (q *Query) Where(args ...interface{}) *Query {
-	var paramSlice []string
-	if args != nil {
-		for i, param := range args {
-			if i == 2 {
-				switch i := param.(type) {
-				case string:
-					paramSlice = append(paramSlice, fmt.Sprintf("%s%s%s", "'", param.(string), "'"))
-				case int:
-					paramSlice = append(paramSlice, strconv.Itoa(i))
-				case int64:
-					paramSlice = append(paramSlice, strconv.FormatInt(i, 10))
-				case float32:
-					paramSlice = append(paramSlice, fmt.Sprint(i))
-				case float64:
-					paramSlice = append(paramSlice, fmt.Sprint(i))
-				case bool:
-					paramSlice = append(paramSlice, strconv.FormatBool(i))
-				default:
-					paramSlice = append(paramSlice, param.(string))
-				}
-			} else {
-				paramSlice = append(paramSlice, param.(string))
-			}
-
-		}
-	}
-	if len(q.where) > 0 {
-		q.where = fmt.Sprintf("%s AND (%s)", q.where, strings.Join(paramSlice, ""))
-	} else {
-		q.where = fmt.Sprintf(" WHERE (%s)", strings.Join(paramSlice, ""))
-	}
-	q.reset()
+	q.addWhere("AND", whereCondition(args))
 	return q
 }
 
@@ -378,101 +516,56 @@ func (q *Query) AndWhere(args ...interface{}) *Query {
 }
 
 // OrWhere defines a where clause on SQL - Additional calls add WHERE () OR () clauses
+// It takes the same arguments as Where
 func (q *Query) OrWhere(args ...interface{}) *Query {
-
-	var paramSlice []string
-	if args != nil {
-		for i, param := range args {
-			if i == 2 {
-				switch i := param.(type) {
-				case string:
-					paramSlice = append(paramSlice, fmt.Sprintf("%s%s%s", "'", param.(string), "'"))
-				case int:
-					paramSlice = append(paramSlice, strconv.Itoa(i))
-				case float32:
-					paramSlice = append(paramSlice, fmt.Sprint(i))
-				case float64:
-					paramSlice = append(paramSlice, fmt.Sprint(i))
-				case bool:
-					paramSlice = append(paramSlice, strconv.FormatBool(i))
-				default:
-					paramSlice = append(paramSlice, param.(string))
-				}
-			} else {
-				paramSlice = append(paramSlice, param.(string))
-			}
-
-		}
-	}
-	if len(q.where) > 0 {
-		q.where = fmt.Sprintf("%s OR (%s)", q.where, strings.Join(paramSlice, ""))
-	} else {
-		q.where = fmt.Sprintf("WHERE (%s)", strings.Join(paramSlice, ""))
-	}
-
-	q.reset()
+	q.addWhere("OR", whereCondition(args))
 	return q
 }
 
 // WhereIn adds a Where clause which selects records IN() the given array
-func (q *Query) WhereIn(col string, args string) *Query {
-	// Return no results, so that when chaining callers
-	// don't have to check for empty arrays
-	if len(args) == 0 {
-		q.Limit(0)
-		q.reset()
+// args is a slice of values, a comma separated string of values, or a *Query used as a subquery.
+// An empty array matches no rows (1=0), so that when chaining callers don't have to check for it.
+func (q *Query) WhereIn(col string, args interface{}) *Query {
+	q.addWhere("AND", In(col, args))
+	return q
+}
+
+// OrWhereIn adds col IN() the given array to the last Where clause with OR
+// ex: Where("device1 = ?", 1).OrWhereIn("device2", ids) makes WHERE ((device1 = ?) OR (device2 IN (?,?)))
+// An empty array adds a group matching no rows (1=0), leaving the rest of the clause as it was.
+func (q *Query) OrWhereIn(col string, args interface{}) *Query {
+	in := In(col, args)
+	if q.whereLast == nil {
+		q.addWhere("AND", in)
 		return q
 	}
-	paramSlice := strings.Split(args, ",")
-	in := ""
-	for _, param := range paramSlice {
-		if _, err := strconv.Atoi(param); err == nil {
-			paramInt, _ := strconv.Atoi(param)
-			in = fmt.Sprintf("%s%d,", in, paramInt)
-		} else {
-			in = fmt.Sprintf("%s%s%s%s,", in, "'", param, "'")
-		}
-	}
-	in = strings.TrimRight(in, ",")
-	sql := fmt.Sprintf("%s IN (%s)", col, in)
-	if len(q.where) > 0 {
-		q.where = fmt.Sprintf("%s AND (%s)", q.where, sql)
-	} else {
-		q.where = fmt.Sprintf("WHERE (%s)", sql)
-	}
+	_, inArgs := in.Build()
+	q.whereLast = Or(q.whereLast, in)
+	sql, _ := q.whereLast.Build()
+	q.where = fmt.Sprintf("%s(%s)", q.whereHead, sql)
+	// The last group ends the where clause, so its new args go last
+	q.args = append(q.args, inArgs...)
 	q.reset()
 	return q
 }
 
-// WhereIn adds a Where clause which selects records IN() the given array
-func (q *Query) OrWhereIn(col string, args string) *Query {
-	// Return no results, so that when chaining callers
-	// don't have to check for empty arrays
-	if len(args) == 0 {
-		q.Limit(0)
-		q.reset()
-		return q
-	}
-	paramSlice := strings.Split(args, ",")
-	in := ""
-	for _, param := range paramSlice {
-		if _, err := strconv.Atoi(param); err == nil {
-			paramInt, _ := strconv.Atoi(param)
-			in = fmt.Sprintf("%s%d,", in, paramInt)
-		} else {
-			in = fmt.Sprintf("%s%s%s%s,", in, "'", param, "'")
-		}
+// addWhere joins cond to the where clause with op as a new group, an empty condition is ignored
+func (q *Query) addWhere(op string, cond Condition) {
+	sql, args := cond.Build()
+	if strings.TrimSpace(sql) == "" {
+		return
 	}
-	in = strings.TrimRight(in, ",")
-	sql := fmt.Sprintf("%s IN (%s)", col, in)
 	if len(q.where) > 0 {
-		// ex: string (device1 IN (3187)) remove ')' at the end to make (device1 IN (3187) or device2 IN (3192))
-		q.where = fmt.Sprintf("%s OR %s)", strings.TrimSuffix(q.where, ")"), sql)
+		q.whereHead = fmt.Sprintf("%s %s ", q.where, op)
+		q.whereFilter = q.whereFilter.add(op, filters(sql))
 	} else {
-		q.where = fmt.Sprintf("WHERE (%s)", sql)
+		q.whereHead = "WHERE "
+		q.whereFilter = whereFilter{last: filters(sql)}
 	}
+	q.whereLast = cond
+	q.where = fmt.Sprintf("%s(%s)", q.whereHead, sql)
+	q.args = append(q.args, args...)
 	q.reset()
-	return q
 }
 
 func (q *Query) InnerJoin(args ...interface{}) *Query {
@@ -583,12 +676,21 @@ func (q *Query) Group(sql string) *Query {
 	return q
 }
 
-// Having defines HAVING sql
-func (q *Query) Having(sql string) *Query {
+// Having defines HAVING sql, given as sql with ? placeholders and its args, or as a Condition
+func (q *Query) Having(having interface{}, args ...interface{}) *Query {
+	sql, values := "", args
+	switch h := having.(type) {
+	case Condition:
+		sql, values = h.Build()
+	case string:
+		sql, values = expandPlaceholders(h, args)
+	}
 	if sql == "" {
 		q.having = ""
+		q.havingArgs = nil
 	} else {
 		q.having = fmt.Sprintf("HAVING %s", sql)
+		q.havingArgs = values
 	}
 	q.reset()
 	return q
@@ -597,6 +699,8 @@ func (q *Query) Having(sql string) *Query {
 //setting from table
 func (q *Query) From(from string) *Query {
 	q.from = from
+	q.fromArgs = nil
+	q.fromAlias = ""
 	q.reset()
 	return q
 }
@@ -604,6 +708,7 @@ func (q *Query) From(from string) *Query {
 // Select defines SELECT  sql
 func (q *Query) Select(field ...string) *Query {
 	q.sel = field
+	q.selArgs = nil
 	q.reset()
 	return q
 }
@@ -625,18 +730,42 @@ func (q *Query) UpdateSql(field string) *Query {
 }
 func (q *Query) ResetSelect() *Query {
 	q.sel = nil
+	q.selArgs = nil
 	return q
 }
 
+// Database returns the registered database name this query runs on
+func (q *Query) Database() string {
+	return q.dbName
+}
+
 // Clear sql/query caches
 func (q *Query) reset() {
 	// clear stored sql
 	q.sql = ""
 }
 
+// dialect returns the dialect of the query's connection, MySQL if it has none
+func (q *Query) dialect() Dialect {
+	if q.d == nil {
+		c, err := Lookup(q.dbName)
+		if err == nil && c.Dialect != nil {
+			q.d = c.Dialect
+		} else {
+			q.d = MySQL
+		}
+	}
+	return q.d
+}
+
+// quote quotes a table name or column name in the query's dialect
+func (q *Query) quote(name string) string {
+	return q.dialect().QuoteField(name)
+}
+
 // Ask model for primary key name to use
 func (q *Query) pk() string {
-	return QuoteField(q.primaryKey)
+	return q.quote(q.primaryKey)
 }
 
 // Ask model for table name to use
@@ -644,15 +773,36 @@ func (q *Query) table() string {
 	if len(q.from) > 0 {
 		return q.from
 	}
-	return QuoteField(q.tableName)
+	return q.quote(q.tableName)
+}
+
+// Ask model for the table or alias to select * from
+func (q *Query) star() string {
+	if len(q.fromAlias) > 0 {
+		return q.quote(q.fromAlias)
+	}
+	return q.table()
+}
+
+// queryArgs returns the args to bind, in the order their clauses appear in the sql
+func (q *Query) queryArgs() []interface{} {
+	if len(q.selArgs) == 0 && len(q.fromArgs) == 0 && len(q.havingArgs) == 0 && len(q.ctes) == 0 && len(q.unions) == 0 {
+		return q.args
+	}
+	args := q.withArgs()
+	if len(q.update) == 0 {
+		args = append(args, q.selArgs...)
+	}
+	args = append(args, q.fromArgs...)
+	args = append(args, q.args...)
+	args = append(args, q.havingArgs...)
+	return append(args, q.unionArgs()...)
 }
 
 // Replace ?
 func (q *Query) replaceArgPlaceholders() {
 	// Match ? and replace with argument placeholder from database
-	for i := range q.args {
-		q.sql = strings.Replace(q.sql, "?", Placeholder(i+1), 1)
-	}
+	q.sql = replacePlaceholders(q.dialect(), q.sql, len(q.queryArgs()))
 }
 
 // Sorts the param names given - map iteration order is explicitly random in Go
@@ -679,10 +829,10 @@ func valuesFromParams(params map[string]interface{}) []interface{} {
 }
 
 // Used for update statements, turn params into sql i.e. "col"=?
-func querySQL(params map[string]interface{}) string {
+func (q *Query) querySQL(params map[string]interface{}) string {
 	var output []string
 	for _, k := range sortedParamKeys(params) {
-		output = append(output, fmt.Sprintf("%s=?", QuoteField(k)))
+		output = append(output, fmt.Sprintf("%s=?", q.quote(k)))
 	}
 	return strings.Join(output, ",")
 }
@@ -721,6 +871,137 @@ func ScanRow(cols []string, rows *sql.Rows) (Result, error) {
 	}
 	return result, nil
 }
+
+// whereSQL turns Where arguments into sql with ? placeholders and the values to bind
+func whereSQL(args []interface{}) (string, []interface{}) {
+	if len(args) == 0 {
+		return "", nil
+	}
+	sql, ok := args[0].(string)
+	if !ok {
+		return fmt.Sprintf("%v", args[0]), nil
+	}
+	// Column, operator, value form
+	if op, isOp := argString(args, 1); isOp && len(args) >= 3 && !strings.Contains(sql, "?") {
+		sql = fmt.Sprintf("%s %s ?", strings.TrimSpace(sql), strings.TrimSpace(op))
+		for _, extra := range args[3:] {
+			sql = fmt.Sprintf("%s%v", sql, extra)
+		}
+		return expandPlaceholders(sql, args[2:3])
+	}
+	return expandPlaceholders(sql, args[1:])
+}
+
+func argString(args []interface{}, i int) (string, bool) {
+	if i >= len(args) {
+		return "", false
+	}
+	s, ok := args[i].(string)
+	return s, ok
+}
+
+// expandPlaceholders replaces the ? bound to a slice with one ? per element, returning the flattened values.
+// An empty slice makes col IN (?) always false (1=0) and col NOT IN (?) always true (1=1), elsewhere it is NULL.
+func expandPlaceholders(sql string, args []interface{}) (string, []interface{}) {
+	var values []interface{}
+	var out strings.Builder
+	i := 0
+	for pos := 0; pos < len(sql); pos++ {
+		c := sql[pos]
+		if c != '?' || i >= len(args) {
+			out.WriteByte(c)
+			continue
+		}
+		if sub, ok := args[i].(*Query); ok {
+			sql, subArgs := sub.subquery()
+			out.WriteString("(" + sql + ")")
+			values = append(values, subArgs...)
+		} else if list, ok := sliceValues(args[i]); ok && len(list) == 0 {
+			head, tail, ok := emptyIn(out.String(), sql[pos+1:])
+			if ok {
+				out.Reset()
+				out.WriteString(head)
+				pos += tail
+			} else {
+				out.WriteString("NULL")
+			}
+		} else if ok {
+			out.WriteString(placeholders(len(list)))
+			values = append(values, list...)
+		} else {
+			out.WriteByte(c)
+			values = append(values, args[i])
+		}
+		i++
+	}
+	return out.String(), values
+}
+
+var (
+	emptyInHead = regexp.MustCompile("(?i)[\\w.`\"]+\\s+(NOT\\s+)?IN\\s*\\(\\s*$")
+	emptyInTail = regexp.MustCompile(`^\s*\)`)
+)
+
+// emptyIn rewrites the col [NOT] IN ( before and the ) after a ? bound to an empty slice as a constant
+// predicate, returning the new head and how many bytes of tail it used
+func emptyIn(head string, tail string) (string, int, bool) {
+	m := emptyInHead.FindStringSubmatchIndex(head)
+	end := emptyInTail.FindStringIndex(tail)
+	if m == nil || end == nil {
+		return "", 0, false
+	}
+	predicate := "1=0"
+	if m[2] >= 0 {
+		predicate = "1=1"
+	}
+	return head[:m[0]] + predicate, end[1], true
+}
+
+// sliceValues returns the elements of a slice argument ([]byte is a single value)
+func sliceValues(arg interface{}) ([]interface{}, bool) {
+	if _, ok := arg.([]byte); ok {
+		return nil, false
+	}
+	val := reflect.ValueOf(arg)
+	if val.Kind() != reflect.Slice && val.Kind() != reflect.Array {
+		return nil, false
+	}
+	values := make([]interface{}, val.Len())
+	for i := range values {
+		values[i] = val.Index(i).Interface()
+	}
+	return values, true
+}
+
+// inValues returns the values of a WhereIn argument - a slice, or a comma separated string where
+// integer items are bound as ints
+func inValues(args interface{}) []interface{} {
+	str, ok := args.(string)
+	if !ok {
+		if values, ok := sliceValues(args); ok {
+			return values
+		}
+		return []interface{}{args}
+	}
+	if len(str) == 0 {
+		return nil
+	}
+	var values []interface{}
+	for _, param := range strings.Split(str, ",") {
+		if paramInt, err := strconv.Atoi(param); err == nil {
+			values = append(values, paramInt)
+		} else {
+			values = append(values, param)
+		}
+	}
+	return values
+}
+
+// placeholders returns n comma separated ? placeholders
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
 func trim(str string) string {
 	re := regexp.MustCompile(`[\s]+`)
 	// replace multi space = 1 space
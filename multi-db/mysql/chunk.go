@@ -0,0 +1,69 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Chunk walks the query in primary key order, passing pages of at most size rows to fn.
+// Pages are fetched with keyset pagination (WHERE pk > last ORDER BY pk LIMIT size), so late pages cost
+// the same as early ones, unlike Offset. Any order and limit set on the query are ignored, and the
+// selected columns must include the primary key.
+func (q *Query) Chunk(size int, fn func(rows []Result) error) error {
+	return q.ChunkContext(context.Background(), size, fn)
+}
+
+// ChunkContext is Chunk bound to ctx
+func (q *Query) ChunkContext(ctx context.Context, size int, fn func(rows []Result) error) error {
+	if size <= 0 {
+		return fmt.Errorf("Error in chunk size %d: must be positive", size)
+	}
+	// Restore the query as given once done
+	where, head, cond, args := q.where, q.whereHead, q.whereLast, q.args
+	filter := q.whereFilter
+	order, limit, offset := q.order, q.limit, q.offset
+	defer func() {
+		q.where, q.whereHead, q.whereLast, q.args = where, head, cond, args
+		q.whereFilter = filter
+		q.order, q.limit, q.offset = order, limit, offset
+		q.reset()
+	}()
+	// The keyset predicate must apply to the whole where clause, which may join its groups with OR
+	grouped := where
+	if where != "" {
+		grouped = fmt.Sprintf("WHERE (%s)", strings.TrimPrefix(where, "WHERE "))
+	}
+	q.offset = ""
+	q.Order(q.pk())
+	q.Limit(size)
+
+	var last interface{}
+	for {
+		if last != nil {
+			q.where, q.whereHead, q.whereLast = grouped, head, cond
+			q.whereFilter = filter
+			q.args = append([]interface{}{}, args...)
+			q.Where(Gt(q.pk(), last))
+		}
+		rows, err := q.ResultsContext(ctx)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		var ok bool
+		last, ok = rows[len(rows)-1][q.primaryKey]
+		if !ok || last == nil {
+			return fmt.Errorf("Error in chunk: primary key %s missing from selected columns", q.primaryKey)
+		}
+		err = fn(rows)
+		if err != nil {
+			return err
+		}
+		if len(rows) < size {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package mysql
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestChunkOrWhere(t *testing.T) {
+	db := openSQLite(t,
+		"CREATE TABLE items (id INTEGER PRIMARY KEY, kind TEXT)",
+		"INSERT INTO items (id, kind) VALUES (1, 'a'), (2, 'b'), (3, 'c'), (4, 'a'), (5, 'c'), (6, 'b'), (7, 'a')",
+	)
+	var ids []string
+	pages := 0
+	q := New("items", "id", db).Where("kind = ?", "a").OrWhere("kind = ?", "b")
+	err := q.Chunk(2, func(rows []Result) error {
+		pages++
+		if pages > 10 {
+			return fmt.Errorf("chunk does not end")
+		}
+		for _, row := range rows {
+			ids = append(ids, fmt.Sprint(row["id"]))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"1", "2", "4", "6", "7"}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("Chunk ids = %v, want %v", ids, want)
+	}
+	// The query is restored once done
+	if n, err := q.Count(); err != nil || n != 5 {
+		t.Errorf("Count after Chunk = %d, %v, want 5", n, err)
+	}
+}
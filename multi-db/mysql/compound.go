@@ -0,0 +1,97 @@
+package mysql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// union is a query joined with UNION or UNION ALL
+type union struct {
+	all   bool
+	query *Query
+}
+
+// cte is a named query in the WITH clause
+type cte struct {
+	name  string
+	query *Query
+}
+
+// Union appends UNION sub to the query - the query's order and limit apply to the whole result
+func (q *Query) Union(sub *Query) *Query {
+	q.unions = append(q.unions, union{query: sub})
+	q.reset()
+	return q
+}
+
+// UnionAll appends UNION ALL sub to the query, keeping duplicate rows
+func (q *Query) UnionAll(sub *Query) *Query {
+	q.unions = append(q.unions, union{all: true, query: sub})
+	q.reset()
+	return q
+}
+
+// With adds a common table expression: WITH name AS (sub) - name may carry a column list, e.g. "tree (id, parent_id)"
+func (q *Query) With(name string, sub *Query) *Query {
+	q.ctes = append(q.ctes, cte{name: name, query: sub})
+	q.reset()
+	return q
+}
+
+// WithRecursive adds a recursive common table expression (MySQL 8+), usually an anchor query UnionAll the recursive step:
+//	anchor := mysql.New("tags", "id").Select("id", "parent_id").Where("parent_id IS NULL")
+//	step := mysql.New("tags", "id").From("tags t JOIN tree ON t.parent_id = tree.id").Select("t.id", "t.parent_id")
+//	q := mysql.New("tree", "id").WithRecursive("tree (id, parent_id)", anchor.UnionAll(step))
+func (q *Query) WithRecursive(name string, sub *Query) *Query {
+	q.recursive = true
+	return q.With(name, sub)
+}
+
+// withSQL renders the WITH clause
+func (q *Query) withSQL() string {
+	if len(q.ctes) == 0 {
+		return ""
+	}
+	var parts []string
+	for _, c := range q.ctes {
+		parts = append(parts, fmt.Sprintf("%s AS (%s)", c.name, c.query.buildSQL()))
+	}
+	with := "WITH"
+	if q.recursive {
+		with = "WITH RECURSIVE"
+	}
+	return fmt.Sprintf("%s %s", with, strings.Join(parts, ", "))
+}
+
+func (q *Query) withArgs() []interface{} {
+	var args []interface{}
+	for _, c := range q.ctes {
+		args = append(args, c.query.queryArgs()...)
+	}
+	return args
+}
+
+// unionSQL renders the UNION clauses - a member with its own order or limit is parenthesized
+func (q *Query) unionSQL() string {
+	sql := ""
+	for _, u := range q.unions {
+		op := "UNION"
+		if u.all {
+			op = "UNION ALL"
+		}
+		member := u.query.buildSQL()
+		if len(u.query.order) > 0 || len(u.query.limit) > 0 || len(u.query.offset) > 0 {
+			member = "(" + member + ")"
+		}
+		sql = strings.TrimSpace(fmt.Sprintf("%s %s %s", sql, op, member))
+	}
+	return sql
+}
+
+func (q *Query) unionArgs() []interface{} {
+	var args []interface{}
+	for _, u := range q.unions {
+		args = append(args, u.query.queryArgs()...)
+	}
+	return args
+}
@@ -0,0 +1,28 @@
+package mysql
+
+import (
+	"testing"
+)
+
+func TestCountUnion(t *testing.T) {
+	db := openSQLite(t,
+		"CREATE TABLE a (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE TABLE b (id INTEGER PRIMARY KEY, name TEXT)",
+		"INSERT INTO a (id, name) VALUES (1, 'x'), (2, 'y'), (3, 'z')",
+		"INSERT INTO b (id, name) VALUES (1, 'x'), (4, 'w')",
+	)
+	n, err := New("a", "id", db).Select("id", "name").Where("id > ?", 0).
+		Union(New("b", "id", db).Select("id", "name").Where("name <> ?", "")).Count()
+	if err != nil || n != 4 {
+		t.Errorf("Count of Union = %d, %v, want 4", n, err)
+	}
+	q := New("a", "id", db).Select("id", "name").UnionAll(New("b", "id", db).Select("id", "name"))
+	n, err = q.Count()
+	if err != nil || n != 5 {
+		t.Errorf("Count of UnionAll = %d, %v, want 5", n, err)
+	}
+	results, err := q.Results()
+	if err != nil || len(results) != 5 {
+		t.Errorf("Results after Count = %d rows, %v, want 5", len(results), err)
+	}
+}
@@ -0,0 +1,180 @@
+package mysql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Condition is a WHERE or HAVING expression, rendered as sql with ? placeholders and the args to bind.
+// Conditions compose with And, Or and Not and can be passed to Where, OrWhere and Having:
+//	q.Where(mysql.Or(mysql.Eq("ad_id", 5), mysql.And(mysql.In("tag_id", ids), mysql.IsNull("deleted_at"))))
+type Condition interface {
+	Build() (string, []interface{})
+}
+
+// expr is a rendered condition
+type expr struct {
+	sql  string
+	args []interface{}
+}
+
+func (e expr) Build() (string, []interface{}) {
+	return e.sql, e.args
+}
+
+// Raw wraps sql with ? placeholders as a condition, a slice arg expands to one placeholder per element
+// and a *Query arg to the parenthesized subquery with its args
+func Raw(sql string, args ...interface{}) Condition {
+	sql, values := expandPlaceholders(sql, args)
+	return expr{sql: sql, args: values}
+}
+
+// And joins conditions with AND, each parenthesized - no conditions is always true
+func And(conds ...Condition) Condition {
+	return join("AND", "1=1", conds)
+}
+
+// Or joins conditions with OR, each parenthesized - no conditions is always false
+func Or(conds ...Condition) Condition {
+	return join("OR", "1=0", conds)
+}
+
+// Not negates a condition
+func Not(cond Condition) Condition {
+	sql, args := cond.Build()
+	return expr{sql: fmt.Sprintf("NOT (%s)", sql), args: args}
+}
+
+// Eq is col = value, or col IS NULL for a nil value
+func Eq(col string, value interface{}) Condition {
+	if value == nil {
+		return IsNull(col)
+	}
+	return compare(col, "=", value)
+}
+
+// Neq is col <> value, or col IS NOT NULL for a nil value
+func Neq(col string, value interface{}) Condition {
+	if value == nil {
+		return IsNotNull(col)
+	}
+	return compare(col, "<>", value)
+}
+
+// Gt is col > value
+func Gt(col string, value interface{}) Condition {
+	return compare(col, ">", value)
+}
+
+// Gte is col >= value
+func Gte(col string, value interface{}) Condition {
+	return compare(col, ">=", value)
+}
+
+// Lt is col < value
+func Lt(col string, value interface{}) Condition {
+	return compare(col, "<", value)
+}
+
+// Lte is col <= value
+func Lte(col string, value interface{}) Condition {
+	return compare(col, "<=", value)
+}
+
+// In is col IN (values...) for a slice or comma separated string of values - no values is always false
+// values may also be a *Query, giving col IN (SELECT ...)
+func In(col string, values interface{}) Condition {
+	if sub, ok := values.(*Query); ok {
+		return Raw(col+" IN ?", sub)
+	}
+	list := inValues(values)
+	if len(list) == 0 {
+		return expr{sql: "1=0"}
+	}
+	return expr{sql: fmt.Sprintf("%s IN (%s)", col, placeholders(len(list))), args: list}
+}
+
+// NotIn is col NOT IN (values...) - no values is always true
+func NotIn(col string, values interface{}) Condition {
+	if sub, ok := values.(*Query); ok {
+		return Raw(col+" NOT IN ?", sub)
+	}
+	list := inValues(values)
+	if len(list) == 0 {
+		return expr{sql: "1=1"}
+	}
+	return expr{sql: fmt.Sprintf("%s NOT IN (%s)", col, placeholders(len(list))), args: list}
+}
+
+// Between is col BETWEEN low AND high
+func Between(col string, low interface{}, high interface{}) Condition {
+	return expr{sql: fmt.Sprintf("%s BETWEEN ? AND ?", col), args: []interface{}{low, high}}
+}
+
+// Like is col LIKE pattern
+func Like(col string, pattern string) Condition {
+	return compare(col, "LIKE", pattern)
+}
+
+// NotLike is col NOT LIKE pattern
+func NotLike(col string, pattern string) Condition {
+	return compare(col, "NOT LIKE", pattern)
+}
+
+// Exists is EXISTS (subquery)
+func Exists(sub *Query) Condition {
+	return Raw("EXISTS ?", sub)
+}
+
+// NotExists is NOT EXISTS (subquery)
+func NotExists(sub *Query) Condition {
+	return Raw("NOT EXISTS ?", sub)
+}
+
+// IsNull is col IS NULL
+func IsNull(col string) Condition {
+	return expr{sql: fmt.Sprintf("%s IS NULL", col)}
+}
+
+// IsNotNull is col IS NOT NULL
+func IsNotNull(col string) Condition {
+	return expr{sql: fmt.Sprintf("%s IS NOT NULL", col)}
+}
+
+func compare(col string, op string, value interface{}) Condition {
+	return expr{sql: fmt.Sprintf("%s %s ?", col, op), args: []interface{}{value}}
+}
+
+func join(op string, empty string, conds []Condition) Condition {
+	var parts []string
+	var args []interface{}
+	for _, cond := range conds {
+		if cond == nil {
+			continue
+		}
+		sql, condArgs := cond.Build()
+		if strings.TrimSpace(sql) == "" {
+			continue
+		}
+		parts = append(parts, sql)
+		args = append(args, condArgs...)
+	}
+	switch len(parts) {
+	case 0:
+		return expr{sql: empty}
+	case 1:
+		return expr{sql: parts[0], args: args}
+	}
+	return expr{sql: "(" + strings.Join(parts, ") "+op+" (") + ")", args: args}
+}
+
+// whereCondition turns Where arguments into a condition
+func whereCondition(args []interface{}) Condition {
+	if len(args) > 0 {
+		if cond, ok := args[0].(Condition); ok {
+			return cond
+		}
+	}
+	sql, values := whereSQL(args)
+	return expr{sql: sql, args: values}
+}
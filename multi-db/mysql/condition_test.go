@@ -0,0 +1,88 @@
+package mysql
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestEmptySlicePlaceholder(t *testing.T) {
+	tests := []struct {
+		sql      string
+		args     []interface{}
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{"id IN (?)", []interface{}{[]int{}}, "1=0", nil},
+		{"kind = ? OR t.id NOT IN ( ? )", []interface{}{"a", []int{}}, "kind = ? OR 1=1", []interface{}{"a"}},
+		{"id in (?) AND kind = ?", []interface{}{[]string{}, "a"}, "1=0 AND kind = ?", []interface{}{"a"}},
+		{"id IN (?, 5)", []interface{}{[]int{}}, "id IN (NULL, 5)", nil},
+		{"id IN (?)", []interface{}{[]int{1, 2}}, "id IN (?,?)", []interface{}{1, 2}},
+	}
+	for _, tt := range tests {
+		sql, args := expandPlaceholders(tt.sql, tt.args)
+		if sql != tt.wantSQL || !reflect.DeepEqual(args, tt.wantArgs) {
+			t.Errorf("expandPlaceholders(%q) = %q %v, want %q %v", tt.sql, sql, args, tt.wantSQL, tt.wantArgs)
+		}
+	}
+}
+
+func TestEmptySliceWhere(t *testing.T) {
+	db := openSQLite(t,
+		"CREATE TABLE items (id INTEGER PRIMARY KEY, kind TEXT)",
+		"INSERT INTO items (id, kind) VALUES (1, 'a'), (2, 'b')",
+	)
+	n, err := New("items", "id", db).Where("id IN (?)", []int{}).Count()
+	if err != nil || n != 0 {
+		t.Errorf("Count with IN () = %d, %v, want 0", n, err)
+	}
+	n, err = New("items", "id", db).Where("id NOT IN (?)", []int{}).Count()
+	if err != nil || n != 2 {
+		t.Errorf("Count with NOT IN () = %d, %v, want 2", n, err)
+	}
+	n, err = New("items", "id", db).Where(In("id", []int{})).Count()
+	if err != nil || n != 0 {
+		t.Errorf("Count with In(id, []) = %d, %v, want 0", n, err)
+	}
+}
+
+func TestWhereInEmpty(t *testing.T) {
+	db := openSQLite(t,
+		"CREATE TABLE items (id INTEGER PRIMARY KEY, kind TEXT)",
+		"INSERT INTO items (id, kind) VALUES (1, 'a'), (2, 'b'), (3, 'a')",
+	)
+	n, err := New("items", "id", db).Where("kind = ?", "a").OrWhereIn("id", []int{}).Count()
+	if err != nil || n != 2 {
+		t.Errorf("Count with OrWhereIn of nothing = %d, %v, want the 2 rows of kind a", n, err)
+	}
+	n, err = New("items", "id", db).WhereIn("id", "").Count()
+	if err != nil || n != 0 {
+		t.Errorf("Count with WhereIn of an empty string = %d, %v, want 0", n, err)
+	}
+
+	read := 0
+	err = New("items", "id", db).WhereIn("id", []int{}).Chunk(2, func(rows []Result) error {
+		read += len(rows)
+		return nil
+	})
+	if err != nil || read != 0 {
+		t.Errorf("Chunk with WhereIn of nothing read %d rows, %v, want 0", read, err)
+	}
+	report, err := Copy(context.Background(), CopySpec{
+		SourceDB: db, SourceTable: "items", SourcePK: "id",
+		TargetDB: db, TargetTable: "items", TargetPK: "id",
+		Filter:    func(q *Query) *Query { return q.WhereIn("id", []int{}) },
+		ChunkSize: 2,
+	})
+	if err != nil || report.Read != 0 {
+		t.Errorf("Copy filtered with WhereIn of nothing = %s, %v, want nothing read", report, err)
+	}
+
+	err = New("items", "id", db).WhereIn("id", []int{}).DeleteAll()
+	if err != nil {
+		t.Errorf("DeleteAll with WhereIn of nothing = %v", err)
+	}
+	if n := count(t, db, "items"); n != 3 {
+		t.Errorf("Count after DeleteAll = %d, want 3", n)
+	}
+}
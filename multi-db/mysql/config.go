@@ -0,0 +1,378 @@
+package mysql
+
+import (
+	"encoding/json"
+	"fmt"
+	mysqldrv "github.com/go-sql-driver/mysql"
+	"gopkg.in/yaml.v3"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConfigEnv names the environment variable holding the config file path
+const ConfigEnv = "MULTIDB_CONFIG"
+
+// DefaultConfigPath is read when neither a path nor ConfigEnv is given
+const DefaultConfigPath = "config.yaml"
+
+// Config lists the connections to register, see LoadConfig
+type Config struct {
+	Default     string             `json:"default" yaml:"default"`
+	Connections []ConnectionConfig `json:"connections" yaml:"connections"`
+}
+
+// ConnectionConfig describes one named connection and its DSN parameters.
+// Durations are Go duration strings such as "5s".
+// Driver is mysql (the default) or postgres, for which charset, parse_time, loc and the read and write
+// timeouts do not apply - pass libpq settings such as sslmode in params.
+//
+// Driver sqlite3 opens the file named by database, or an in-memory database shared by the pool when database
+// is empty or :memory: (it lives while the pool holds a connection). Timeout is the busy timeout and params
+// are go-sqlite3 options such as _journal_mode; the host, credentials and other MySQL settings are ignored.
+//
+// Only the MySQL driver is linked in, import multi-db/driver/postgres or multi-db/driver/sqlite for the others.
+type ConnectionConfig struct {
+	Name         string            `json:"name" yaml:"name"`
+	Driver       string            `json:"driver" yaml:"driver"`
+	Host         string            `json:"host" yaml:"host"`
+	Port         int               `json:"port" yaml:"port"`
+	Username     string            `json:"username" yaml:"username"`
+	Password     string            `json:"password" yaml:"password"`
+	PasswordFile string            `json:"password_file" yaml:"password_file"`
+	Database     string            `json:"database" yaml:"database"`
+	Charset      string            `json:"charset" yaml:"charset"`
+	ParseTime    bool              `json:"parse_time" yaml:"parse_time"`
+	Loc          string            `json:"loc" yaml:"loc"`
+	Timeout      string            `json:"timeout" yaml:"timeout"`
+	ReadTimeout  string            `json:"read_timeout" yaml:"read_timeout"`
+	WriteTimeout string            `json:"write_timeout" yaml:"write_timeout"`
+	Params       map[string]string `json:"params" yaml:"params"`
+
+	// UpsertRowAlias selects the MySQL8 dialect on a mysql connection
+	UpsertRowAlias bool `json:"upsert_row_alias" yaml:"upsert_row_alias"`
+
+	// Pool settings
+	MaxOpenConns    int    `json:"max_open_conns" yaml:"max_open_conns"`
+	MaxIdleConns    int    `json:"max_idle_conns" yaml:"max_idle_conns"`
+	ConnMaxLifetime string `json:"conn_max_lifetime" yaml:"conn_max_lifetime"`
+}
+
+// LoadConfig reads a YAML or JSON (by extension) config file and applies environment overrides.
+// An empty path falls back to $MULTIDB_CONFIG and then DefaultConfigPath.
+//
+// Every field of a connection can be overridden with MULTIDB_<NAME>_<FIELD>, where NAME is the
+// upper-cased connection name and FIELD the upper-cased yaml key, e.g. MULTIDB_BG_EMAIL_PASSWORD_FILE.
+// Params are set one by one with MULTIDB_<NAME>_PARAMS_<KEY>, the key being lower-cased, e.g.
+// MULTIDB_BG_EMAIL_PARAMS_SSLMODE or MULTIDB_CACHE_PARAMS__JOURNAL_MODE.
+// MULTIDB_DEFAULT overrides the default connection.
+func LoadConfig(path string) (*Config, error) {
+	if path == "" {
+		path = os.Getenv(ConfigEnv)
+	}
+	if path == "" {
+		path = DefaultConfigPath
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading config %s: %s", path, err)
+	}
+	config := &Config{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, config)
+	default:
+		err = yaml.Unmarshal(data, config)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing config %s: %s", path, err)
+	}
+	err = config.applyEnv()
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Connect registers every connection in the config, then sets the default connection
+func (c *Config) Connect() error {
+	for _, cc := range c.Connections {
+		err := cc.Register()
+		if err != nil {
+			return err
+		}
+	}
+	if c.Default != "" {
+		return SetDefault(c.Default)
+	}
+	return nil
+}
+
+// ConnectConfig loads the config at path (see LoadConfig) and registers its connections
+func ConnectConfig(path string) error {
+	config, err := LoadConfig(path)
+	if err != nil {
+		return err
+	}
+	return config.Connect()
+}
+
+// Register opens and registers this connection, applying the pool settings
+func (cc ConnectionConfig) Register() error {
+	dsn, err := cc.DSN()
+	if err != nil {
+		return err
+	}
+	if d, _ := DialectFor(cc.driver()); cc.UpsertRowAlias && d.Name() != "mysql" {
+		return fmt.Errorf("Error in %s upsert_row_alias: only for mysql, not %s", cc.Name, cc.driver())
+	}
+	err = Register(cc.Name, cc.driver(), dsn)
+	if err != nil {
+		return err
+	}
+	db, err := DB(cc.Name)
+	if err != nil {
+		return err
+	}
+	if cc.UpsertRowAlias {
+		err = SetDialect(cc.Name, MySQL8)
+		if err != nil {
+			return err
+		}
+	}
+	if cc.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(cc.MaxOpenConns)
+	}
+	if cc.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(cc.MaxIdleConns)
+	}
+	if cc.ConnMaxLifetime != "" {
+		d, err := time.ParseDuration(cc.ConnMaxLifetime)
+		if err != nil {
+			return fmt.Errorf("Error in %s conn_max_lifetime: %s", cc.Name, err)
+		}
+		db.SetConnMaxLifetime(d)
+	}
+	return nil
+}
+
+// DSN builds the driver data source name for this connection
+func (cc ConnectionConfig) DSN() (string, error) {
+	d, err := DialectFor(cc.driver())
+	if err != nil {
+		return "", fmt.Errorf("Error in %s driver: %s", cc.Name, err)
+	}
+	password, err := cc.password()
+	if err != nil {
+		return "", err
+	}
+	switch d.Name() {
+	case "postgres":
+		return cc.postgresDSN(password)
+	case "sqlite":
+		return cc.sqliteDSN()
+	}
+	port := cc.Port
+	if port == 0 {
+		port = 3306
+	}
+	dc := mysqldrv.NewConfig()
+	dc.User = cc.Username
+	dc.Passwd = password
+	dc.Net = "tcp"
+	dc.Addr = net.JoinHostPort(cc.Host, strconv.Itoa(port))
+	dc.DBName = cc.Database
+	dc.ParseTime = cc.ParseTime
+	if cc.Loc != "" {
+		dc.Loc, err = time.LoadLocation(cc.Loc)
+		if err != nil {
+			return "", fmt.Errorf("Error in %s loc: %s", cc.Name, err)
+		}
+	}
+	durations := []struct {
+		name  string
+		value string
+		dst   *time.Duration
+	}{
+		{"timeout", cc.Timeout, &dc.Timeout},
+		{"read_timeout", cc.ReadTimeout, &dc.ReadTimeout},
+		{"write_timeout", cc.WriteTimeout, &dc.WriteTimeout},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		*d.dst, err = time.ParseDuration(d.value)
+		if err != nil {
+			return "", fmt.Errorf("Error in %s %s: %s", cc.Name, d.name, err)
+		}
+	}
+	if cc.Charset != "" || len(cc.Params) > 0 {
+		dc.Params = make(map[string]string)
+		for k, v := range cc.Params {
+			dc.Params[k] = v
+		}
+		if cc.Charset != "" {
+			dc.Params["charset"] = cc.Charset
+		}
+	}
+	return dc.FormatDSN(), nil
+}
+
+// postgresDSN builds a postgres:// url, the connect timeout is rounded to seconds
+func (cc ConnectionConfig) postgresDSN(password string) (string, error) {
+	port := cc.Port
+	if port == 0 {
+		port = 5432
+	}
+	u := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(cc.Username, password),
+		Host:   net.JoinHostPort(cc.Host, strconv.Itoa(port)),
+		Path:   "/" + cc.Database,
+	}
+	params := url.Values{}
+	for k, v := range cc.Params {
+		params.Set(k, v)
+	}
+	if cc.Timeout != "" {
+		d, err := time.ParseDuration(cc.Timeout)
+		if err != nil {
+			return "", fmt.Errorf("Error in %s timeout: %s", cc.Name, err)
+		}
+		params.Set("connect_timeout", strconv.Itoa(int((d+time.Second-1)/time.Second)))
+	}
+	u.RawQuery = params.Encode()
+	return u.String(), nil
+}
+
+// sqliteDSN builds a file: uri, in-memory databases are named after the connection so each is distinct
+func (cc ConnectionConfig) sqliteDSN() (string, error) {
+	path := cc.Database
+	params := url.Values{}
+	for k, v := range cc.Params {
+		params.Set(k, v)
+	}
+	if path == "" || path == ":memory:" {
+		// Every connection of the pool must see the same database
+		path = cc.Name
+		params.Set("mode", "memory")
+		params.Set("cache", "shared")
+	}
+	if cc.Timeout != "" {
+		d, err := time.ParseDuration(cc.Timeout)
+		if err != nil {
+			return "", fmt.Errorf("Error in %s timeout: %s", cc.Name, err)
+		}
+		params.Set("_busy_timeout", strconv.FormatInt(int64(d/time.Millisecond), 10))
+	}
+	if len(params) == 0 {
+		return "file:" + path, nil
+	}
+	return "file:" + path + "?" + params.Encode(), nil
+}
+
+func (cc ConnectionConfig) driver() string {
+	if cc.Driver == "" {
+		return Driver
+	}
+	return cc.Driver
+}
+
+// password reads the secret file when one is given, trimming the trailing newline
+func (cc ConnectionConfig) password() (string, error) {
+	if cc.PasswordFile == "" {
+		return cc.Password, nil
+	}
+	data, err := os.ReadFile(cc.PasswordFile)
+	if err != nil {
+		return "", fmt.Errorf("Error reading %s password_file: %s", cc.Name, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// Apply MULTIDB_* environment overrides
+func (c *Config) applyEnv() error {
+	if v, ok := os.LookupEnv("MULTIDB_DEFAULT"); ok {
+		c.Default = v
+	}
+	for i := range c.Connections {
+		cc := &c.Connections[i]
+		prefix := envPrefix(cc.Name)
+		strs := map[string]*string{
+			"DRIVER":            &cc.Driver,
+			"HOST":              &cc.Host,
+			"USERNAME":          &cc.Username,
+			"PASSWORD":          &cc.Password,
+			"PASSWORD_FILE":     &cc.PasswordFile,
+			"DATABASE":          &cc.Database,
+			"CHARSET":           &cc.Charset,
+			"LOC":               &cc.Loc,
+			"TIMEOUT":           &cc.Timeout,
+			"READ_TIMEOUT":      &cc.ReadTimeout,
+			"WRITE_TIMEOUT":     &cc.WriteTimeout,
+			"CONN_MAX_LIFETIME": &cc.ConnMaxLifetime,
+		}
+		for key, dst := range strs {
+			if v, ok := os.LookupEnv(prefix + key); ok {
+				*dst = v
+			}
+		}
+		ints := map[string]*int{
+			"PORT":           &cc.Port,
+			"MAX_OPEN_CONNS": &cc.MaxOpenConns,
+			"MAX_IDLE_CONNS": &cc.MaxIdleConns,
+		}
+		for key, dst := range ints {
+			if v, ok := os.LookupEnv(prefix + key); ok {
+				n, err := strconv.Atoi(v)
+				if err != nil {
+					return fmt.Errorf("Error in %s%s: %s", prefix, key, err)
+				}
+				*dst = n
+			}
+		}
+		bools := map[string]*bool{
+			"PARSE_TIME":       &cc.ParseTime,
+			"UPSERT_ROW_ALIAS": &cc.UpsertRowAlias,
+		}
+		for key, dst := range bools {
+			if v, ok := os.LookupEnv(prefix + key); ok {
+				b, err := strconv.ParseBool(v)
+				if err != nil {
+					return fmt.Errorf("Error in %s%s: %s", prefix, key, err)
+				}
+				*dst = b
+			}
+		}
+		for _, kv := range os.Environ() {
+			key, v, _ := strings.Cut(kv, "=")
+			if !strings.HasPrefix(key, prefix+"PARAMS_") {
+				continue
+			}
+			if cc.Params == nil {
+				cc.Params = make(map[string]string)
+			}
+			cc.Params[strings.ToLower(strings.TrimPrefix(key, prefix+"PARAMS_"))] = v
+		}
+	}
+	return nil
+}
+
+// envPrefix turns a connection name like bg_email into MULTIDB_BG_EMAIL_
+func envPrefix(name string) string {
+	mapped := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, name)
+	return "MULTIDB_" + mapped + "_"
+}
@@ -0,0 +1,126 @@
+package mysql
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDSN(t *testing.T) {
+	tests := []struct {
+		name string
+		cc   ConnectionConfig
+		want string
+	}{
+		{"mysql", ConnectionConfig{Name: "a", Host: "db", Username: "u", Password: "p", Database: "d", Charset: "utf8mb4", ParseTime: true, Timeout: "5s"},
+			"u:p@tcp(db:3306)/d?parseTime=true&timeout=5s&charset=utf8mb4"},
+		{"postgres", ConnectionConfig{Name: "a", Driver: "postgres", Host: "db", Username: "u", Password: "p w", Database: "d", Timeout: "1500ms", Params: map[string]string{"sslmode": "disable"}},
+			"postgres://u:p%20w@db:5432/d?connect_timeout=2&sslmode=disable"},
+		{"sqlite file", ConnectionConfig{Name: "a", Driver: "sqlite3", Database: "/tmp/a.db", Timeout: "2s"},
+			"file:/tmp/a.db?_busy_timeout=2000"},
+		{"sqlite memory", ConnectionConfig{Name: "cache", Driver: "sqlite3", Database: ":memory:"},
+			"file:cache?cache=shared&mode=memory"},
+	}
+	for _, tt := range tests {
+		got, err := tt.cc.DSN()
+		if err != nil {
+			t.Errorf("%s: DSN error %s", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: DSN = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	for _, cc := range []ConnectionConfig{
+		{Name: "a", Driver: "oracle"},
+		{Name: "a", Timeout: "5"},
+		{Name: "a", Driver: "postgres", Timeout: "x"},
+		{Name: "a", Loc: "Nowhere/City"},
+	} {
+		if _, err := cc.DSN(); err == nil {
+			t.Errorf("DSN of %+v returned no error", cc)
+		}
+	}
+}
+
+func TestPasswordFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret")
+	err := os.WriteFile(path, []byte("s3cret\r\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc := ConnectionConfig{Name: "a", Host: "db", Username: "u", Password: "ignored", PasswordFile: path}
+	got, err := cc.DSN()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "u:s3cret@tcp(db:3306)/"; got != want {
+		t.Errorf("DSN = %s, want %s", got, want)
+	}
+	cc.PasswordFile = path + ".missing"
+	if _, err = cc.DSN(); err == nil {
+		t.Error("DSN with a missing password_file returned no error")
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	t.Setenv("MULTIDB_DEFAULT", "bg-email")
+	t.Setenv("MULTIDB_BG_EMAIL_HOST", "db2")
+	t.Setenv("MULTIDB_BG_EMAIL_PORT", "3307")
+	t.Setenv("MULTIDB_BG_EMAIL_PARSE_TIME", "true")
+	t.Setenv("MULTIDB_BG_EMAIL_PARAMS_SSLMODE", "require")
+	t.Setenv("MULTIDB_BG_EMAIL_PARAMS__JOURNAL_MODE", "WAL")
+	t.Setenv("MULTIDB_OTHER_HOST", "other")
+
+	config := &Config{Connections: []ConnectionConfig{{Name: "bg-email", Host: "db", Params: map[string]string{"sslmode": "disable", "x": "y"}}}}
+	err := config.applyEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc := config.Connections[0]
+	if config.Default != "bg-email" || cc.Host != "db2" || cc.Port != 3307 || !cc.ParseTime {
+		t.Errorf("config after applyEnv = %+v", config)
+	}
+	want := map[string]string{"sslmode": "require", "_journal_mode": "WAL", "x": "y"}
+	if len(cc.Params) != len(want) {
+		t.Errorf("Params = %v, want %v", cc.Params, want)
+	}
+	for k, v := range want {
+		if cc.Params[k] != v {
+			t.Errorf("Params[%s] = %q, want %q", k, cc.Params[k], v)
+		}
+	}
+
+	t.Setenv("MULTIDB_BG_EMAIL_PORT", "x")
+	if err = config.applyEnv(); err == nil {
+		t.Error("applyEnv with a bad port returned no error")
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	yml := filepath.Join(dir, "config.yaml")
+	err := os.WriteFile(yml, []byte("default: b\nconnections:\n  - name: a\n    host: h\n  - name: b\n    driver: sqlite3\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	json := filepath.Join(dir, "config.json")
+	err = os.WriteFile(json, []byte(`{"default": "b", "connections": [{"name": "a", "host": "h"}, {"name": "b", "driver": "sqlite3"}]}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{yml, json} {
+		config, err := LoadConfig(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if config.Default != "b" || len(config.Connections) != 2 || config.Connections[0].Host != "h" || config.Connections[1].Driver != "sqlite3" {
+			t.Errorf("LoadConfig(%s) = %+v", path, config)
+		}
+	}
+	t.Setenv(ConfigEnv, yml)
+	if _, err = LoadConfig(""); err != nil {
+		t.Errorf("LoadConfig from %s: %s", ConfigEnv, err)
+	}
+}
@@ -0,0 +1,196 @@
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Connection is a named database pool held in the registry
+type Connection struct {
+	Name   string
+	Driver string
+	DSN    string
+	DB     *sql.DB
+
+	// Dialect renders the sql for this connection, chosen from Driver
+	Dialect Dialect
+
+	// max_allowed_packet of the server, read once by batch inserts
+	maxPacket int64
+}
+
+var (
+	connMu      sync.RWMutex
+	connections = make(map[string]*Connection)
+	defaultName string
+)
+
+// Register opens a pool for dsn and stores it under name, replacing (and closing) any previous pool with that name.
+// Queries not bound to a transaction look their pool up when they run, so they move to the new pool.
+// The first connection registered becomes the default used by New() when no database is given.
+func Register(name string, driver string, dsn string) error {
+	d, err := DialectFor(driver)
+	if err != nil {
+		return fmt.Errorf("Error opening database %s: %s", name, err)
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return fmt.Errorf("Error opening database %s: %s", name, err)
+	}
+	return register(&Connection{Name: name, Driver: driver, DSN: dsn, DB: db, Dialect: d})
+}
+
+// RegisterDB stores an already opened pool under name
+func RegisterDB(name string, driver string, db *sql.DB) error {
+	if db == nil {
+		return fmt.Errorf("Error registering database %s: nil pool", name)
+	}
+	d, err := DialectFor(driver)
+	if err != nil {
+		return fmt.Errorf("Error registering database %s: %s", name, err)
+	}
+	return register(&Connection{Name: name, Driver: driver, DB: db, Dialect: d})
+}
+
+func register(c *Connection) error {
+	if c.Name == "" {
+		return fmt.Errorf("Error registering database: empty name")
+	}
+	connMu.Lock()
+	old := connections[c.Name]
+	connections[c.Name] = c
+	if defaultName == "" {
+		defaultName = c.Name
+	}
+	connMu.Unlock()
+	if old != nil && old.DB != c.DB {
+		old.DB.Close()
+	}
+	return nil
+}
+
+// SetDialect sets the dialect of the connection registered under name, e.g. MySQL8 for a MySQL 8.0.19+ server.
+// Queries built before keep the dialect they were built with.
+func SetDialect(name string, d Dialect) error {
+	connMu.Lock()
+	defer connMu.Unlock()
+	c, ok := connections[name]
+	if !ok {
+		return fmt.Errorf("Database %s is not registered", name)
+	}
+	c.Dialect = d
+	return nil
+}
+
+// SetDefault sets the connection used by New() when no database name is given
+func SetDefault(name string) error {
+	connMu.Lock()
+	defer connMu.Unlock()
+	if _, ok := connections[name]; !ok {
+		return fmt.Errorf("Database %s is not registered", name)
+	}
+	defaultName = name
+	return nil
+}
+
+// Lookup returns the connection registered under name, or the default connection if name is empty
+func Lookup(name string) (*Connection, error) {
+	connMu.RLock()
+	defer connMu.RUnlock()
+	if name == "" {
+		name = defaultName
+	}
+	c, ok := connections[name]
+	if !ok {
+		return nil, fmt.Errorf("Database %s is not registered", name)
+	}
+	return c, nil
+}
+
+// DB returns the pool registered under name
+func DB(name string) (*sql.DB, error) {
+	c, err := Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return c.DB, nil
+}
+
+// Names returns the registered connection names in sorted order
+func Names() []string {
+	connMu.RLock()
+	defer connMu.RUnlock()
+	names := make([]string, 0, len(connections))
+	for name := range connections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Ping checks the connection registered under name
+func Ping(name string) error {
+	db, err := DB(name)
+	if err != nil {
+		return err
+	}
+	return db.Ping()
+}
+
+// PingAll checks every registered connection, returning the errors keyed by name (empty if all are healthy)
+func PingAll() map[string]error {
+	failed := make(map[string]error)
+	for _, name := range Names() {
+		if err := Ping(name); err != nil {
+			failed[name] = err
+		}
+	}
+	return failed
+}
+
+// Reconnect pings the named connection, returning the error if the server is still unreachable.
+// database/sql replaces broken connections of the pool by itself, so the pool is kept: queries and
+// transactions holding it go on working once the server is back.
+func Reconnect(name string) error {
+	c, err := Lookup(name)
+	if err != nil {
+		return err
+	}
+	err = c.DB.Ping()
+	if err != nil {
+		return fmt.Errorf("Error reconnecting database %s: %s", name, err)
+	}
+	return nil
+}
+
+// Close closes and unregisters the named connection
+func Close(name string) error {
+	connMu.Lock()
+	c, ok := connections[name]
+	delete(connections, name)
+	if defaultName == name {
+		defaultName = ""
+	}
+	connMu.Unlock()
+	if !ok {
+		return fmt.Errorf("Database %s is not registered", name)
+	}
+	return c.DB.Close()
+}
+
+// CloseAll closes every registered connection, returning the first error met
+func CloseAll() error {
+	var first error
+	for _, name := range Names() {
+		err := Close(name)
+		if err != nil {
+			fmt.Println("ERROR close "+name+": ", err.Error())
+			if first == nil {
+				first = err
+			}
+		}
+	}
+	return first
+}
@@ -0,0 +1,45 @@
+package mysql
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRegisterReplacesPool(t *testing.T) {
+	cc := ConnectionConfig{Name: "replaced", Driver: "sqlite3", Database: filepath.Join(t.TempDir(), "replaced.db")}
+	err := cc.Register()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Close(cc.Name)
+	db, err := DB(cc.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec("CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := New("items", "id", cc.Name)
+
+	// Registering the name again closes the first pool, the query moves to the new one
+	err = cc.Register()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = q.Insert(map[string]interface{}{"name": "a"})
+	if err != nil {
+		t.Fatalf("Insert after Register = %s", err)
+	}
+	if n, err := q.Count(); err != nil || n != 1 {
+		t.Errorf("Count after Register = %d, %v, want 1", n, err)
+	}
+
+	err = Reconnect(cc.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := q.Count(); err != nil || n != 1 {
+		t.Errorf("Count after Reconnect = %d, %v, want 1", n, err)
+	}
+}
@@ -0,0 +1,226 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// copyErrorLimit caps the row errors kept in a CopyReport
+const copyErrorLimit = 100
+
+// CopySpec describes a table-to-table copy between registered databases
+type CopySpec struct {
+	SourceDB    string
+	SourceTable string
+	SourcePK    string
+
+	TargetDB    string
+	TargetTable string
+	TargetPK    string
+
+	// Columns maps source columns to target columns, nil copies every source column under its own name
+	Columns map[string]string
+
+	// Filter narrows the source query, e.g. func(q *Query) *Query { return q.Where("ad_id > 100") }
+	Filter func(q *Query) *Query
+
+	// Skip drops a source row when it returns true
+	Skip func(row Result) bool
+
+	// ChunkSize reads the source in primary key pages of this many rows (see Query.Chunk) instead of one
+	// long-running select, 0 streams the source with a single query
+	ChunkSize int
+
+	// BatchSize is the number of rows per multi-row INSERT, 0 uses DefaultBatchSize
+	BatchSize int
+
+	// Mode is the insert statement used on the target - ModeUpsert, ModeIgnore or ModeReplace make the
+	// copy re-runnable. UpdateColumns lists the columns ModeUpsert updates, nil updates every column but
+	// the primary key
+	Mode          WriteMode
+	UpdateColumns []string
+
+	// Atomic writes every row inside one transaction on the target (an XA transaction on MySQL), so any failure
+	// rolls back the whole copy
+	Atomic bool
+}
+
+// CopyReport counts the rows handled by Copy
+type CopyReport struct {
+	Read    int64
+	Written int64
+	Skipped int64
+	Failed  int64
+
+	// Replaced and Ignored count written rows that replaced or were dropped for an existing row (ModeReplace, ModeIgnore)
+	Replaced int64
+	Ignored  int64
+
+	// Errors holds the first row errors met (up to 100)
+	Errors []error
+}
+
+func (r CopyReport) String() string {
+	return fmt.Sprintf("read %d, written %d (replaced %d, ignored %d), skipped %d, failed %d", r.Read, r.Written, r.Replaced, r.Ignored, r.Skipped, r.Failed)
+}
+
+// Copy streams rows from the source table into the target table.
+// Rows are written in batches: the rows of a failed batch are retried one by one, those still refused count
+// as failed in the report and the copy goes on. If spec.Atomic is set the first failure rolls back every
+// written row and is returned, with Written, Replaced and Ignored reset to 0.
+func Copy(ctx context.Context, spec CopySpec) (CopyReport, error) {
+	var report CopyReport
+	if !spec.Atomic {
+		err := copyRows(ctx, spec, nil, &report)
+		return report, err
+	}
+	c, err := Lookup(spec.TargetDB)
+	if err != nil {
+		return report, err
+	}
+	if c.Dialect.Name() == "mysql" {
+		err = WithXA(ctx, []string{spec.TargetDB}, func(x *XA) error {
+			return copyRows(ctx, spec, func(q *Query) *Query { return q.UseXA(x) }, &report)
+		})
+	} else {
+		err = WithTxContext(ctx, spec.TargetDB, nil, func(tx *Tx) error {
+			return copyRows(ctx, spec, func(q *Query) *Query { return q.UseTx(tx) }, &report)
+		})
+	}
+	if err != nil {
+		// Nothing was kept
+		report.Written, report.Replaced, report.Ignored = 0, 0, 0
+	}
+	return report, err
+}
+
+// copyRows runs the copy, bind puts the target queries inside the atomic transaction
+func copyRows(ctx context.Context, spec CopySpec, bind func(q *Query) *Query, report *CopyReport) error {
+	source := New(spec.SourceTable, spec.SourcePK, spec.SourceDB)
+	if source == nil {
+		return fmt.Errorf("Database %s is not registered", spec.SourceDB)
+	}
+	if New(spec.TargetTable, spec.TargetPK, spec.TargetDB) == nil {
+		return fmt.Errorf("Database %s is not registered", spec.TargetDB)
+	}
+	if len(spec.Columns) > 0 {
+		var cols []string
+		for col := range spec.Columns {
+			cols = append(cols, source.quote(col))
+		}
+		if _, ok := spec.Columns[spec.SourcePK]; !ok && spec.ChunkSize > 0 {
+			cols = append(cols, source.quote(spec.SourcePK))
+		}
+		sort.Strings(cols)
+		source.Select(cols...)
+	}
+	if spec.Filter != nil {
+		source = spec.Filter(source)
+	}
+
+	size := spec.BatchSize
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+	batch := make([]map[string]interface{}, 0, size)
+	each := func(row Result) error {
+		report.Read++
+		if spec.Skip != nil && spec.Skip(row) {
+			report.Skipped++
+			return nil
+		}
+		batch = append(batch, spec.mapRow(row))
+		if len(batch) < size {
+			return nil
+		}
+		err := spec.write(ctx, bind, batch, report)
+		batch = batch[:0]
+		return err
+	}
+	var err error
+	if spec.ChunkSize > 0 {
+		err = source.ChunkContext(ctx, spec.ChunkSize, func(rows []Result) error {
+			for _, row := range rows {
+				err := each(row)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	} else {
+		err = source.EachContext(ctx, each)
+	}
+	if err != nil {
+		return err
+	}
+	return spec.write(ctx, bind, batch, report)
+}
+
+// write inserts one batch into the target. Outside an atomic copy the rows of a failed statement are retried
+// one by one, so only the rows the target refuses count as failed.
+func (spec CopySpec) write(ctx context.Context, bind func(q *Query) *Query, batch []map[string]interface{}, report *CopyReport) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	target := New(spec.TargetTable, spec.TargetPK, spec.TargetDB).BatchSize(len(batch))
+	if bind != nil {
+		target = bind(target)
+	}
+	result, err := target.writeBatch(ctx, batch, spec.Mode, spec.UpdateColumns)
+	report.written(result)
+	if err == nil {
+		return nil
+	}
+	rest := batch[result.Rows:]
+	if bind != nil || ctx.Err() != nil {
+		report.failed(int64(len(rest)), err)
+		return err
+	}
+	if len(rest) == 1 {
+		report.failed(1, err)
+		return nil
+	}
+	for _, row := range rest {
+		result, err = target.writeBatch(ctx, []map[string]interface{}{row}, spec.Mode, spec.UpdateColumns)
+		report.written(result)
+		if err != nil {
+			report.failed(1, err)
+			if ctx.Err() != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// written counts the rows of a successful write
+func (r *CopyReport) written(result WriteResult) {
+	r.Written += result.Rows
+	r.Replaced += result.Replaced
+	r.Ignored += result.Skipped
+}
+
+// failed counts n rows refused with err
+func (r *CopyReport) failed(n int64, err error) {
+	r.Failed += n
+	if len(r.Errors) < copyErrorLimit {
+		r.Errors = append(r.Errors, err)
+	}
+}
+
+// mapRow renames the source columns to their target columns
+func (spec CopySpec) mapRow(row Result) map[string]interface{} {
+	params := make(map[string]interface{}, len(row))
+	for col, v := range row {
+		if len(spec.Columns) == 0 {
+			params[col] = v
+			continue
+		}
+		if target, ok := spec.Columns[col]; ok {
+			params[target] = v
+		}
+	}
+	return params
+}
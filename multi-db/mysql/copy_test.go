@@ -0,0 +1,108 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+)
+
+const copySchema = "CREATE TABLE tags (id INTEGER PRIMARY KEY, ad_id INTEGER, tag TEXT)"
+
+// copySource opens a database holding 5 tags and an empty copy of the table
+func copySource(t *testing.T) string {
+	return openSQLite(t,
+		copySchema,
+		"CREATE TABLE tags_copy (id INTEGER PRIMARY KEY, ad_id INTEGER, tag TEXT)",
+		"INSERT INTO tags (id, ad_id, tag) VALUES (1, 1, 'a'), (2, 1, 'b'), (3, 2, 'c'), (4, 3, 'd'), (5, 3, 'e')",
+	)
+}
+
+func TestCopy(t *testing.T) {
+	tests := []struct {
+		name      string
+		atomic    bool
+		chunkSize int
+		otherDB   bool
+	}{
+		{"stream", false, 0, false},
+		{"chunks", false, 2, false},
+		{"atomic", true, 0, false},
+		{"atomic chunks", true, 2, false},
+		{"atomic other database", true, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := copySource(t)
+			spec := CopySpec{
+				SourceDB: src, SourceTable: "tags", SourcePK: "id",
+				TargetDB: src, TargetTable: "tags_copy", TargetPK: "id",
+				ChunkSize: tt.chunkSize, BatchSize: 2, Atomic: tt.atomic,
+			}
+			if tt.otherDB {
+				spec.TargetDB = openSQLite(t, copySchema)
+				spec.TargetTable = "tags"
+			}
+			report, err := Copy(context.Background(), spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.Read != 5 || report.Written != 5 || report.Failed != 0 {
+				t.Errorf("report = %s, want 5 read and written", report)
+			}
+			rows, err := New(spec.TargetTable, "id", spec.TargetDB).Order("id").Results()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != 5 || rows[4]["tag"] != "e" {
+				t.Errorf("target rows = %v, want the 5 source rows", rows)
+			}
+		})
+	}
+}
+
+func TestCopyFailedRows(t *testing.T) {
+	src := openSQLite(t,
+		copySchema,
+		"CREATE TABLE tags_copy (id INTEGER PRIMARY KEY, ad_id INTEGER, tag TEXT NOT NULL)",
+		"INSERT INTO tags (id, ad_id, tag) VALUES (1, 1, 'a'), (2, 1, 'b'), (3, 2, 'c'), (4, 3, 'd'), (5, 3, NULL)",
+		"INSERT INTO tags_copy (id, ad_id, tag) VALUES (4, 0, 'x')",
+	)
+	// Row 4 is a duplicate and row 5 has no tag, the rows sharing their batches are still written
+	spec := CopySpec{
+		SourceDB: src, SourceTable: "tags", SourcePK: "id",
+		TargetDB: src, TargetTable: "tags_copy", TargetPK: "id",
+		BatchSize: 2,
+	}
+	report, err := Copy(context.Background(), spec)
+	if err != nil || report.Read != 5 || report.Written != 3 || report.Failed != 2 || len(report.Errors) != 2 {
+		t.Errorf("Copy = %s (%d errors), %v, want 3 written and 2 failed", report, len(report.Errors), err)
+	}
+	if n := count(t, src, "tags_copy"); n != 4 {
+		t.Errorf("target rows = %d, want 4", n)
+	}
+}
+
+func TestCopyAtomicRollback(t *testing.T) {
+	src := openSQLite(t,
+		copySchema,
+		"CREATE TABLE tags_copy (id INTEGER PRIMARY KEY, ad_id INTEGER, tag TEXT NOT NULL)",
+		"INSERT INTO tags (id, ad_id, tag) VALUES (1, 1, 'a'), (2, 1, 'b'), (3, 2, 'c'), (4, 3, 'd'), (5, 3, NULL)",
+		"INSERT INTO tags_copy (id, ad_id, tag) VALUES (1, 0, 'x')",
+	)
+	// Row 1 is replaced, then row 5 fails and rolls everything back
+	spec := CopySpec{
+		SourceDB: src, SourceTable: "tags", SourcePK: "id",
+		TargetDB: src, TargetTable: "tags_copy", TargetPK: "id",
+		BatchSize: 2, Mode: ModeReplace, Atomic: true,
+	}
+	report, err := Copy(context.Background(), spec)
+	if err == nil {
+		t.Fatal("atomic Copy hitting a NOT NULL column returned no error")
+	}
+	if report.Written != 0 || report.Replaced != 0 || report.Ignored != 0 || report.Failed != 1 {
+		t.Errorf("report = %s, want nothing written and 1 failed", report)
+	}
+	rows, err := New("tags_copy", "id", src).Results()
+	if err != nil || len(rows) != 1 || rows[0]["tag"] != "x" {
+		t.Errorf("target rows after the rollback = %v, %v, want the original row", rows, err)
+	}
+}
@@ -0,0 +1,98 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Cursor streams the rows of a query one at a time, so memory stays bounded whatever the table size.
+// NB caller must call defer cursor.Close()
+type Cursor struct {
+	rows *sql.Rows
+	cols []string
+	err  error
+}
+
+// Cursor executes the query and returns a cursor over its rows
+func (q *Query) Cursor() (*Cursor, error) {
+	return q.CursorContext(context.Background())
+}
+
+// CursorContext is Cursor bound to ctx
+func (q *Query) CursorContext(ctx context.Context) (*Cursor, error) {
+	rows, cols, err := q.ResultsSimpleContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Cursor{rows: rows, cols: cols}, nil
+}
+
+// Each streams the query rows to fn, stopping at the first error returned by fn
+func (q *Query) Each(fn func(Result) error) error {
+	return q.EachContext(context.Background(), fn)
+}
+
+// EachContext is Each bound to ctx
+func (q *Query) EachContext(ctx context.Context, fn func(Result) error) error {
+	c, err := q.CursorContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	for c.Next() {
+		result, err := c.Result()
+		if err != nil {
+			return err
+		}
+		err = fn(result)
+		if err != nil {
+			return err
+		}
+	}
+	return c.Err()
+}
+
+// Next advances to the next row, returning false when the rows are exhausted or an error occurred
+func (c *Cursor) Next() bool {
+	if c.err != nil {
+		return false
+	}
+	return c.rows.Next()
+}
+
+// Columns returns the column names of the rows
+func (c *Cursor) Columns() []string {
+	return c.cols
+}
+
+// Scan copies the current row into dest, as sql.Rows.Scan
+func (c *Cursor) Scan(dest ...interface{}) error {
+	err := c.rows.Scan(dest...)
+	if err != nil {
+		c.err = fmt.Errorf("Error scanning row: %s", err)
+	}
+	return c.err
+}
+
+// Result returns the current row as a Result
+func (c *Cursor) Result() (Result, error) {
+	result, err := ScanRow(c.cols, c.rows)
+	if err != nil {
+		c.err = err
+	}
+	return result, err
+}
+
+// Err returns the error met while iterating, if any
+func (c *Cursor) Err() error {
+	if c.err != nil {
+		return c.err
+	}
+	return c.rows.Err()
+}
+
+// Close releases the rows, it is safe to call more than once
+func (c *Cursor) Close() error {
+	return c.rows.Close()
+}
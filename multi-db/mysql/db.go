@@ -1,8 +1,10 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 )
 
 var debug bool
@@ -11,45 +13,75 @@ func init() {
 	debug = false
 }
 
-// Query SQL execute - NB caller must call use defer rows.Close() with rows returned
-func QuerySql(query string, args ...interface{}) (*sql.Rows, error) {
-	if DbConnection == nil {
+// executor runs statements - implemented by *sql.DB, *sql.Tx and *sql.Conn
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// defaultExecutor returns the pool of the default registered connection
+func defaultExecutor() (executor, error) {
+	db, err := DB("")
+	if err != nil {
 		return nil, fmt.Errorf("No database available")
 	}
-	if debug {
-		fmt.Println("QUERY:", query, "ARGS", args)
-	}
-	stmt, err := DbConnection.Prepare(query)
+	return db, nil
+}
+
+// Query SQL execute on the default database - NB caller must call use defer rows.Close() with rows returned
+func QuerySql(query string, args ...interface{}) (*sql.Rows, error) {
+	return QuerySqlContext(context.Background(), query, args...)
+}
+
+// QuerySqlContext is QuerySql bound to ctx
+func QuerySqlContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	db, err := defaultExecutor()
 	if err != nil {
 		return nil, err
 	}
-	defer stmt.Close()
+	return querySql(ctx, db, query, args...)
+}
 
-	rows, err := stmt.Query(args...)
+// Exec on the default database - use this for non-select statements
+func Exec(query string, args ...interface{}) (sql.Result, error) {
+	return ExecContext(context.Background(), query, args...)
+}
 
+// ExecContext is Exec bound to ctx
+func ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	db, err := defaultExecutor()
 	if err != nil {
 		return nil, err
 	}
-
-	return rows, err
+	return exec(ctx, db, query, args...)
 }
 
-// Exec - use this for non-select statements
-func Exec(query string, args ...interface{}) (sql.Result, error) {
-	if DbConnection == nil {
-		return nil, fmt.Errorf("No database available.")
+func querySql(ctx context.Context, db executor, query string, args ...interface{}) (*sql.Rows, error) {
+	if db == nil {
+		return nil, fmt.Errorf("No database available")
 	}
 	if debug {
 		fmt.Println("QUERY:", query, "ARGS", args)
 	}
+	// No explicit prepare: closing the statement of a Tx or Conn would close its rows before they are read
+	rows, err := db.QueryContext(ctx, query, args...)
 
-	stmt, err := DbConnection.Prepare(query)
 	if err != nil {
 		return nil, err
 	}
-	defer stmt.Close()
 
-	result, err := stmt.Exec(args...)
+	return rows, err
+}
+
+func exec(ctx context.Context, db executor, query string, args ...interface{}) (sql.Result, error) {
+	if db == nil {
+		return nil, fmt.Errorf("No database available.")
+	}
+	if debug {
+		fmt.Println("QUERY:", query, "ARGS", args)
+	}
+
+	result, err := db.ExecContext(ctx, query, args...)
 
 	if err != nil {
 		return result, err
@@ -57,20 +89,32 @@ func Exec(query string, args ...interface{}) (sql.Result, error) {
 	return result, err
 }
 
-// QuoteField quotes a table name or column name
+// QuoteField quotes a table name or column name for the default database
 func QuoteField(name string) string {
-	return fmt.Sprintf("`%s`", name)
+	return defaultDialect().QuoteField(name)
 }
 
+// Insert on the default database, returning the last insert id.
+// On databases without LastInsertId (see Dialect.Returning) query must end with RETURNING id.
 func Insert(query string, args ...interface{}) (id int64, err error) {
+	return InsertContext(context.Background(), query, args...)
+}
 
-	tx, err := DbConnection.Begin()
+// InsertContext is Insert bound to ctx
+func InsertContext(ctx context.Context, query string, args ...interface{}) (id int64, err error) {
+	db, err := defaultExecutor()
 	if err != nil {
 		return 0, err
 	}
+	return insert(ctx, db, defaultDialect(), query, args...)
+}
 
+func insert(ctx context.Context, db executor, d Dialect, query string, args ...interface{}) (id int64, err error) {
+	if d.Returning() {
+		return insertReturning(ctx, db, query, args...)
+	}
 	// Execute the sql using db
-	result, err := Exec(query, args...)
+	result, err := exec(ctx, db, query, args...)
 	if err != nil {
 		return 0, err
 	}
@@ -80,17 +124,50 @@ func Insert(query string, args ...interface{}) (id int64, err error) {
 		return 0, err
 	}
 
-	err = tx.Commit()
+	return id, nil
+
+}
+
+// insertReturning runs an INSERT ... RETURNING id and reads the id of the first row
+func insertReturning(ctx context.Context, db executor, query string, args ...interface{}) (int64, error) {
+	rows, err := querySql(ctx, db, query, args...)
 	if err != nil {
 		return 0, err
 	}
-
-	return id, nil
-
+	defer rows.Close()
+	var id interface{}
+	if rows.Next() {
+		err = rows.Scan(&id)
+		if err != nil {
+			return 0, err
+		}
+	}
+	err = rows.Err()
+	if err != nil {
+		return 0, err
+	}
+	// Keys that are not integers have no insert id
+	n, _ := id.(int64)
+	return n, nil
 }
+
+// ReplaceArgPlaceholder replaces the ? placeholders of sql, one per arg, with those of the default database
 func ReplaceArgPlaceholder(sql string, args []interface{}) string {
-	return sql
+	return replacePlaceholders(defaultDialect(), sql, len(args))
 }
+
+// Placeholder returns the i-th bind placeholder (counting from 1) of the default database
 func Placeholder(i int) string {
-	return "?"
+	return defaultDialect().Placeholder(i)
+}
+
+// replacePlaceholders replaces the first n ? placeholders of sql with those of d
+func replacePlaceholders(d Dialect, sql string, n int) string {
+	if d.Placeholder(1) == "?" {
+		return sql
+	}
+	for i := 0; i < n; i++ {
+		sql = strings.Replace(sql, "?", d.Placeholder(i+1), 1)
+	}
+	return sql
 }
@@ -0,0 +1,49 @@
+package mysql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	_ "multi-db/driver/sqlite"
+)
+
+// sqliteSeq numbers the databases opened by openSQLite
+var sqliteSeq int
+
+// openSQLite registers a new in-memory SQLite database named after the test and runs stmts on it
+func openSQLite(t *testing.T, stmts ...string) string {
+	t.Helper()
+	sqliteSeq++
+	name := fmt.Sprintf("%s_%d", strings.Replace(t.Name(), "/", "_", -1), sqliteSeq)
+	err := ConnectionConfig{Name: name, Driver: "sqlite3"}.Register()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Close(name) })
+	db, err := DB(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range stmts {
+		_, err = db.Exec(stmt)
+		if err != nil {
+			t.Fatalf("%s: %s", stmt, err)
+		}
+	}
+	return name
+}
+
+// count returns the number of rows in table
+func count(t *testing.T, dbName string, table string) int64 {
+	t.Helper()
+	n, err := New(table, "id", dbName).Count()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+// errRollback makes WithTx roll back
+var errRollback = errors.New("rollback")
@@ -0,0 +1,293 @@
+package mysql
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Dialect renders the sql that differs between database servers.
+// Queries use the dialect of the connection they were built on, chosen from its driver name (see RegisterDialect).
+type Dialect interface {
+	// Name is the dialect name, e.g. mysql or postgres
+	Name() string
+	// Placeholder returns the bind placeholder for the i-th arg, counting from 1
+	Placeholder(i int) string
+	// QuoteField quotes a table name or column name
+	QuoteField(name string) string
+	// LimitOffset renders the LIMIT and OFFSET clauses, an empty limit or offset is unset
+	LimitOffset(limit string, offset string) string
+	// InsertVerb is the head of an insert statement for mode, e.g. INSERT IGNORE INTO
+	InsertVerb(mode WriteMode) string
+	// OnConflict renders the clause following VALUES for mode on a table keyed by pk - cols are the
+	// inserted columns and updateCols the columns an upsert updates
+	OnConflict(mode WriteMode, pk string, cols []string, updateCols []string) string
+	// Returning is true when the server has INSERT ... RETURNING, which then reads insert ids rather than LastInsertId
+	Returning() bool
+	// MaxArgs is the most placeholders a prepared statement may hold
+	MaxArgs() int
+	// UpdateLimit is true when UPDATE and DELETE take ORDER BY and LIMIT
+	UpdateLimit() bool
+}
+
+var (
+	// MySQL is the dialect of MySQL and MariaDB
+	MySQL Dialect = mysqlDialect{}
+	// MySQL8 is the dialect of MySQL 8.0.19+: upserts use the row alias (INSERT ... AS new ON DUPLICATE KEY
+	// UPDATE col=new.col) instead of the VALUES(col) function, which MySQL 8 deprecates.
+	// Set it on a connection with SetDialect or the upsert_row_alias config setting.
+	MySQL8 Dialect = mysqlDialect{rowAlias: true}
+	// Postgres is the dialect of PostgreSQL
+	Postgres Dialect = postgresDialect{}
+	// SQLite is the dialect of SQLite 3.35+
+	SQLite Dialect = sqliteDialect{}
+)
+
+var (
+	dialectMu sync.RWMutex
+	dialects  = map[string]Dialect{
+		"mysql":    MySQL,
+		"postgres": Postgres,
+		"pgx":      Postgres,
+		"sqlite3":  SQLite,
+		"sqlite":   SQLite,
+	}
+)
+
+// RegisterDialect sets the dialect used by connections opened with driver
+func RegisterDialect(driver string, d Dialect) {
+	dialectMu.Lock()
+	defer dialectMu.Unlock()
+	dialects[driver] = d
+}
+
+// DialectFor returns the dialect registered for driver
+func DialectFor(driver string) (Dialect, error) {
+	dialectMu.RLock()
+	defer dialectMu.RUnlock()
+	d, ok := dialects[driver]
+	if !ok {
+		return nil, fmt.Errorf("No dialect registered for driver %s", driver)
+	}
+	return d, nil
+}
+
+// defaultDialect returns the dialect of the default connection, MySQL if none is registered
+func defaultDialect() Dialect {
+	c, err := Lookup("")
+	if err != nil || c.Dialect == nil {
+		return MySQL
+	}
+	return c.Dialect
+}
+
+type mysqlDialect struct {
+	rowAlias bool
+}
+
+func (mysqlDialect) Name() string {
+	return "mysql"
+}
+
+func (mysqlDialect) Placeholder(i int) string {
+	return "?"
+}
+
+func (mysqlDialect) QuoteField(name string) string {
+	return fmt.Sprintf("`%s`", strings.Replace(name, "`", "``", -1))
+}
+
+func (mysqlDialect) LimitOffset(limit string, offset string) string {
+	if limit == "" && offset == "" {
+		return ""
+	}
+	if limit == "" {
+		// MySQL has no OFFSET without LIMIT, this is the documented way to skip rows to the end
+		limit = "18446744073709551615"
+	}
+	if offset == "" {
+		return fmt.Sprintf("LIMIT %s", limit)
+	}
+	return fmt.Sprintf("LIMIT %s OFFSET %s", limit, offset)
+}
+
+func (mysqlDialect) InsertVerb(mode WriteMode) string {
+	switch mode {
+	case ModeIgnore:
+		return "INSERT IGNORE INTO"
+	case ModeReplace:
+		return "REPLACE INTO"
+	}
+	return "INSERT INTO"
+}
+
+func (d mysqlDialect) OnConflict(mode WriteMode, pk string, cols []string, updateCols []string) string {
+	if mode != ModeUpsert {
+		return ""
+	}
+	var sets []string
+	for _, col := range updateCols {
+		if d.rowAlias {
+			sets = append(sets, fmt.Sprintf("%s=%s.%s", d.QuoteField(col), upsertAlias, d.QuoteField(col)))
+		} else {
+			sets = append(sets, fmt.Sprintf("%s=VALUES(%s)", d.QuoteField(col), d.QuoteField(col)))
+		}
+	}
+	if len(sets) == 0 {
+		// Nothing to update, keep the existing row
+		sets = append(sets, fmt.Sprintf("%s=%s", d.QuoteField(pk), d.QuoteField(pk)))
+	}
+	alias := ""
+	if d.rowAlias {
+		alias = fmt.Sprintf(" AS %s", upsertAlias)
+	}
+	return fmt.Sprintf("%s ON DUPLICATE KEY UPDATE %s", alias, strings.Join(sets, ","))
+}
+
+func (mysqlDialect) Returning() bool {
+	return false
+}
+
+func (mysqlDialect) MaxArgs() int {
+	return 65535
+}
+
+func (mysqlDialect) UpdateLimit() bool {
+	return true
+}
+
+// postgresDialect has no INSERT IGNORE or REPLACE: ModeIgnore is ON CONFLICT DO NOTHING and ModeReplace
+// updates every column of the conflicting row, which WriteResult counts as replaced from its xmax.
+// Upserts and replaces conflict on the primary key.
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string {
+	return "postgres"
+}
+
+func (postgresDialect) Placeholder(i int) string {
+	return fmt.Sprintf("$%d", i)
+}
+
+func (postgresDialect) QuoteField(name string) string {
+	return fmt.Sprintf(`"%s"`, strings.Replace(name, `"`, `""`, -1))
+}
+
+func (postgresDialect) LimitOffset(limit string, offset string) string {
+	var parts []string
+	if limit != "" {
+		parts = append(parts, "LIMIT "+limit)
+	}
+	if offset != "" {
+		parts = append(parts, "OFFSET "+offset)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (postgresDialect) InsertVerb(mode WriteMode) string {
+	return "INSERT INTO"
+}
+
+func (d postgresDialect) OnConflict(mode WriteMode, pk string, cols []string, updateCols []string) string {
+	switch mode {
+	case ModeIgnore:
+		return " ON CONFLICT DO NOTHING"
+	case ModeReplace:
+		updateCols = nil
+		for _, col := range cols {
+			if col != pk {
+				updateCols = append(updateCols, col)
+			}
+		}
+	case ModeUpsert:
+	default:
+		return ""
+	}
+	return conflictUpdate(d, pk, updateCols)
+}
+
+func (postgresDialect) Returning() bool {
+	return true
+}
+
+func (postgresDialect) MaxArgs() int {
+	return 65535
+}
+
+func (postgresDialect) UpdateLimit() bool {
+	return false
+}
+
+// sqliteDialect skips and replaces rows with INSERT OR IGNORE and INSERT OR REPLACE. SQLite does not count
+// the rows a replace deletes, so WriteResult counts as replaced the rows whose primary key was already in the
+// table (not those replacing a row on another unique key). Upserts conflict on the primary key.
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string {
+	return "sqlite"
+}
+
+func (sqliteDialect) Placeholder(i int) string {
+	return "?"
+}
+
+func (sqliteDialect) QuoteField(name string) string {
+	return fmt.Sprintf(`"%s"`, strings.Replace(name, `"`, `""`, -1))
+}
+
+func (sqliteDialect) LimitOffset(limit string, offset string) string {
+	if limit == "" && offset == "" {
+		return ""
+	}
+	if limit == "" {
+		// SQLite has no OFFSET without LIMIT, a negative limit is none
+		limit = "-1"
+	}
+	if offset == "" {
+		return fmt.Sprintf("LIMIT %s", limit)
+	}
+	return fmt.Sprintf("LIMIT %s OFFSET %s", limit, offset)
+}
+
+func (sqliteDialect) InsertVerb(mode WriteMode) string {
+	switch mode {
+	case ModeIgnore:
+		return "INSERT OR IGNORE INTO"
+	case ModeReplace:
+		return "INSERT OR REPLACE INTO"
+	}
+	return "INSERT INTO"
+}
+
+func (d sqliteDialect) OnConflict(mode WriteMode, pk string, cols []string, updateCols []string) string {
+	if mode != ModeUpsert {
+		return ""
+	}
+	return conflictUpdate(d, pk, updateCols)
+}
+
+func (sqliteDialect) Returning() bool {
+	return true
+}
+
+func (sqliteDialect) MaxArgs() int {
+	// SQLITE_MAX_VARIABLE_NUMBER since SQLite 3.32
+	return 32766
+}
+
+func (sqliteDialect) UpdateLimit() bool {
+	// Only in builds with SQLITE_ENABLE_UPDATE_DELETE_LIMIT
+	return false
+}
+
+// conflictUpdate renders ON CONFLICT (pk) DO UPDATE, shared by PostgreSQL and SQLite
+func conflictUpdate(d Dialect, pk string, updateCols []string) string {
+	if len(updateCols) == 0 {
+		return fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", d.QuoteField(pk))
+	}
+	var sets []string
+	for _, col := range updateCols {
+		sets = append(sets, fmt.Sprintf("%s=EXCLUDED.%s", d.QuoteField(col), d.QuoteField(col)))
+	}
+	return fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s", d.QuoteField(pk), strings.Join(sets, ","))
+}
@@ -0,0 +1,156 @@
+package mysql
+
+import (
+	"testing"
+)
+
+func TestPostgresPlaceholders(t *testing.T) {
+	db := openSQLite(t)
+	pg := func(q *Query) *Query {
+		q.d = Postgres
+		return q
+	}
+	tests := []struct {
+		name string
+		q    *Query
+		want string
+	}{
+		{
+			"where, subquery and having",
+			pg(New("items", "id", db)).Select("kind").Where("price > ?", 1).
+				WhereIn("id", New("tags", "id", db).Select("item_id").Where("tag = ?", "x")).
+				Group("kind").Having("COUNT(*) > ?", 2),
+			`SELECT kind FROM "items" WHERE (price > $1) AND (id IN (SELECT item_id FROM "tags" WHERE (tag = $2))) ` +
+				`GROUP BY kind HAVING COUNT(*) > $3;`,
+		},
+		{
+			"select subquery",
+			pg(New("items", "id", db)).Select("id").
+				SelectSub(New("tags", "id", db).Select("COUNT(*)").Where("tag = ?", "x"), "n").
+				Where("id IN (?)", []int{1, 2}),
+			`SELECT id,(SELECT COUNT(*) FROM "tags" WHERE (tag = $1)) AS "n" FROM "items" WHERE (id IN ($2,$3));`,
+		},
+		{
+			"with and union",
+			pg(New("items", "id", db)).With("recent", New("items", "id", db).Select("id").Where("id > ?", 10)).
+				Select("id").Where("id IN (SELECT id FROM recent)").Where("price < ?", 5).
+				Union(New("archive", "id", db).Select("id").Where("price < ?", 5)).Limit(3),
+			`WITH recent AS (SELECT id FROM "items" WHERE (id > $1)) SELECT id FROM "items" ` +
+				`WHERE (id IN (SELECT id FROM recent)) AND (price < $2) UNION SELECT id FROM "archive" WHERE (price < $3) LIMIT 3;`,
+		},
+		{
+			"every clause",
+			pg(argsQuery(db)),
+			`WITH cheap AS (SELECT id FROM "items" WHERE (price < $1)) ` +
+				`SELECT kind,(SELECT COUNT(*) FROM "items" WHERE (kind = $2)) AS "n" ` +
+				`FROM (SELECT "items".* FROM "items" WHERE (kind <> $3)) AS "s" ` +
+				`WHERE (price > $4) AND (id IN (SELECT id FROM "cheap" WHERE (id <> $5))) ` +
+				`GROUP BY kind HAVING COUNT(*) > $6 ` +
+				`UNION SELECT kind,price FROM "items" WHERE (kind = $7) ORDER BY kind;`,
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.q.QueryString(); got != tt.want {
+			t.Errorf("%s =\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestInsertSQL(t *testing.T) {
+	type sql struct {
+		verb, insert, upsert, upsertNothing string
+	}
+	tests := []struct {
+		d    Dialect
+		want map[WriteMode]sql
+	}{
+		{MySQL, map[WriteMode]sql{
+			ModeInsert:  {verb: "INSERT INTO"},
+			ModeIgnore:  {verb: "INSERT IGNORE INTO"},
+			ModeReplace: {verb: "REPLACE INTO"},
+			ModeUpsert: {
+				verb:          "INSERT INTO",
+				upsert:        " ON DUPLICATE KEY UPDATE `name`=VALUES(`name`)",
+				upsertNothing: " ON DUPLICATE KEY UPDATE `id`=`id`",
+			},
+		}},
+		{MySQL8, map[WriteMode]sql{
+			ModeInsert:  {verb: "INSERT INTO"},
+			ModeIgnore:  {verb: "INSERT IGNORE INTO"},
+			ModeReplace: {verb: "REPLACE INTO"},
+			ModeUpsert: {
+				verb:          "INSERT INTO",
+				upsert:        " AS new ON DUPLICATE KEY UPDATE `name`=new.`name`",
+				upsertNothing: " AS new ON DUPLICATE KEY UPDATE `id`=`id`",
+			},
+		}},
+		{Postgres, map[WriteMode]sql{
+			ModeInsert: {verb: "INSERT INTO"},
+			ModeIgnore: {verb: "INSERT INTO", insert: " ON CONFLICT DO NOTHING"},
+			ModeReplace: {
+				verb:   "INSERT INTO",
+				insert: ` ON CONFLICT ("id") DO UPDATE SET "name"=EXCLUDED."name","price"=EXCLUDED."price"`,
+			},
+			ModeUpsert: {
+				verb:          "INSERT INTO",
+				upsert:        ` ON CONFLICT ("id") DO UPDATE SET "name"=EXCLUDED."name"`,
+				upsertNothing: ` ON CONFLICT ("id") DO NOTHING`,
+			},
+		}},
+		{SQLite, map[WriteMode]sql{
+			ModeInsert:  {verb: "INSERT INTO"},
+			ModeIgnore:  {verb: "INSERT OR IGNORE INTO"},
+			ModeReplace: {verb: "INSERT OR REPLACE INTO"},
+			ModeUpsert: {
+				verb:          "INSERT INTO",
+				upsert:        ` ON CONFLICT ("id") DO UPDATE SET "name"=EXCLUDED."name"`,
+				upsertNothing: ` ON CONFLICT ("id") DO NOTHING`,
+			},
+		}},
+	}
+	cols := []string{"id", "name", "price"}
+	for _, tt := range tests {
+		for mode, want := range tt.want {
+			if got := tt.d.InsertVerb(mode); got != want.verb {
+				t.Errorf("%s InsertVerb(%v) = %q, want %q", tt.d.Name(), mode, got, want.verb)
+			}
+			if mode == ModeUpsert {
+				if got := tt.d.OnConflict(mode, "id", cols, []string{"name"}); got != want.upsert {
+					t.Errorf("%s upsert OnConflict = %q, want %q", tt.d.Name(), got, want.upsert)
+				}
+				if got := tt.d.OnConflict(mode, "id", cols, nil); got != want.upsertNothing {
+					t.Errorf("%s upsert OnConflict without columns = %q, want %q", tt.d.Name(), got, want.upsertNothing)
+				}
+				continue
+			}
+			if got := tt.d.OnConflict(mode, "id", cols, nil); got != want.insert {
+				t.Errorf("%s OnConflict(%v) = %q, want %q", tt.d.Name(), mode, got, want.insert)
+			}
+		}
+	}
+}
+
+func TestLimitOffset(t *testing.T) {
+	tests := []struct {
+		d                   Dialect
+		limit, offset, both string
+	}{
+		{MySQL, "LIMIT 5", "LIMIT 18446744073709551615 OFFSET 10", "LIMIT 5 OFFSET 10"},
+		{Postgres, "LIMIT 5", "OFFSET 10", "LIMIT 5 OFFSET 10"},
+		{SQLite, "LIMIT 5", "LIMIT -1 OFFSET 10", "LIMIT 5 OFFSET 10"},
+	}
+	for _, tt := range tests {
+		if got := tt.d.LimitOffset("5", ""); got != tt.limit {
+			t.Errorf("%s limit = %q, want %q", tt.d.Name(), got, tt.limit)
+		}
+		if got := tt.d.LimitOffset("", "10"); got != tt.offset {
+			t.Errorf("%s offset = %q, want %q", tt.d.Name(), got, tt.offset)
+		}
+		if got := tt.d.LimitOffset("5", "10"); got != tt.both {
+			t.Errorf("%s limit and offset = %q, want %q", tt.d.Name(), got, tt.both)
+		}
+		if got := tt.d.LimitOffset("", ""); got != "" {
+			t.Errorf("%s no limit = %q, want none", tt.d.Name(), got)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package mysql
+
+import "context"
+
+// InsertIgnore inserts a record with INSERT IGNORE, skipping it if it hits a duplicate key.
+// The result tells whether it was Inserted or Skipped.
+func (q *Query) InsertIgnore(params map[string]interface{}) (WriteResult, error) {
+	return q.InsertIgnoreContext(context.Background(), params)
+}
+
+// InsertIgnoreContext is InsertIgnore bound to ctx
+func (q *Query) InsertIgnoreContext(ctx context.Context, params map[string]interface{}) (WriteResult, error) {
+	return q.writeBatch(ctx, []map[string]interface{}{params}, ModeIgnore, nil)
+}
+
+// InsertIgnoreObject inserts a struct with builder tags with INSERT IGNORE, see InsertIgnore
+func (q *Query) InsertIgnoreObject(object interface{}) (WriteResult, error) {
+	return q.InsertIgnoreObjectContext(context.Background(), object)
+}
+
+// InsertIgnoreObjectContext is InsertIgnoreObject bound to ctx
+func (q *Query) InsertIgnoreObjectContext(ctx context.Context, object interface{}) (WriteResult, error) {
+	params, err := paramsFromObject(object)
+	if err != nil {
+		return WriteResult{}, err
+	}
+	return q.InsertIgnoreContext(ctx, params)
+}
+
+// InsertIgnoreBatch inserts rows with multi-row INSERT IGNORE statements, split as InsertBatch
+func (q *Query) InsertIgnoreBatch(rows []map[string]interface{}) (WriteResult, error) {
+	return q.InsertIgnoreBatchContext(context.Background(), rows)
+}
+
+// InsertIgnoreBatchContext is InsertIgnoreBatch bound to ctx
+func (q *Query) InsertIgnoreBatchContext(ctx context.Context, rows []map[string]interface{}) (WriteResult, error) {
+	return q.writeBatch(ctx, rows, ModeIgnore, nil)
+}
+
+// InsertIgnoreObjects inserts a slice of structs with multi-row INSERT IGNORE statements
+func (q *Query) InsertIgnoreObjects(objects interface{}) (WriteResult, error) {
+	return q.InsertIgnoreObjectsContext(context.Background(), objects)
+}
+
+// InsertIgnoreObjectsContext is InsertIgnoreObjects bound to ctx
+func (q *Query) InsertIgnoreObjectsContext(ctx context.Context, objects interface{}) (WriteResult, error) {
+	rows, err := paramsFromObjects(objects)
+	if err != nil {
+		return WriteResult{}, err
+	}
+	return q.InsertIgnoreBatchContext(ctx, rows)
+}
+
+// Replace writes a record with REPLACE INTO, deleting any existing row with the same key first.
+// The result tells whether it was Inserted or Replaced.
+func (q *Query) Replace(params map[string]interface{}) (WriteResult, error) {
+	return q.ReplaceContext(context.Background(), params)
+}
+
+// ReplaceContext is Replace bound to ctx
+func (q *Query) ReplaceContext(ctx context.Context, params map[string]interface{}) (WriteResult, error) {
+	return q.writeBatch(ctx, []map[string]interface{}{params}, ModeReplace, nil)
+}
+
+// ReplaceObject writes a struct with builder tags with REPLACE INTO, see Replace
+func (q *Query) ReplaceObject(object interface{}) (WriteResult, error) {
+	return q.ReplaceObjectContext(context.Background(), object)
+}
+
+// ReplaceObjectContext is ReplaceObject bound to ctx
+func (q *Query) ReplaceObjectContext(ctx context.Context, object interface{}) (WriteResult, error) {
+	params, err := paramsFromObject(object)
+	if err != nil {
+		return WriteResult{}, err
+	}
+	return q.ReplaceContext(ctx, params)
+}
+
+// ReplaceBatch writes rows with multi-row REPLACE INTO statements, split as InsertBatch
+func (q *Query) ReplaceBatch(rows []map[string]interface{}) (WriteResult, error) {
+	return q.ReplaceBatchContext(context.Background(), rows)
+}
+
+// ReplaceBatchContext is ReplaceBatch bound to ctx
+func (q *Query) ReplaceBatchContext(ctx context.Context, rows []map[string]interface{}) (WriteResult, error) {
+	return q.writeBatch(ctx, rows, ModeReplace, nil)
+}
+
+// ReplaceObjects writes a slice of structs with multi-row REPLACE INTO statements
+func (q *Query) ReplaceObjects(objects interface{}) (WriteResult, error) {
+	return q.ReplaceObjectsContext(context.Background(), objects)
+}
+
+// ReplaceObjectsContext is ReplaceObjects bound to ctx
+func (q *Query) ReplaceObjectsContext(ctx context.Context, objects interface{}) (WriteResult, error) {
+	rows, err := paramsFromObjects(objects)
+	if err != nil {
+		return WriteResult{}, err
+	}
+	return q.ReplaceBatchContext(ctx, rows)
+}
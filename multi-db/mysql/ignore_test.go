@@ -0,0 +1,58 @@
+package mysql
+
+import (
+	"testing"
+)
+
+func TestWriteResultCount(t *testing.T) {
+	tests := []struct {
+		mode           WriteMode
+		rows, affected int64
+		want           WriteResult
+	}{
+		{ModeInsert, 3, 3, WriteResult{Inserted: 3}},
+		{ModeIgnore, 3, 2, WriteResult{Inserted: 2, Skipped: 1}},
+		// MySQL counts 2 for a replaced row: one delete plus one insert
+		{ModeReplace, 3, 4, WriteResult{Inserted: 2, Replaced: 1}},
+		{ModeReplace, 3, 6, WriteResult{Replaced: 3}},
+		// A row deleting rows on several unique keys
+		{ModeReplace, 2, 5, WriteResult{Replaced: 2}},
+	}
+	for _, tt := range tests {
+		r := WriteResult{Rows: tt.rows, Affected: tt.affected}
+		r.count(tt.mode)
+		if r.Inserted != tt.want.Inserted || r.Replaced != tt.want.Replaced || r.Skipped != tt.want.Skipped {
+			t.Errorf("count(%v) of %d rows, %d affected = %+v, want %+v", tt.mode, tt.rows, tt.affected, r, tt.want)
+		}
+	}
+}
+
+func TestIgnoreReplaceCounts(t *testing.T) {
+	db := openSQLite(t,
+		"CREATE TABLE tags (id INTEGER PRIMARY KEY, tag TEXT)",
+		"INSERT INTO tags (id, tag) VALUES (3, 'old')",
+	)
+	rows := func(ids ...int) []map[string]interface{} {
+		var rows []map[string]interface{}
+		for _, id := range ids {
+			rows = append(rows, map[string]interface{}{"id": id, "tag": "new"})
+		}
+		return rows
+	}
+	result, err := New("tags", "id", db).InsertIgnoreBatch(rows(2, 3, 4))
+	if err != nil || result.Rows != 3 || result.Inserted != 2 || result.Skipped != 1 {
+		t.Errorf("InsertIgnoreBatch = %+v, %v, want 2 inserted and 1 skipped", result, err)
+	}
+	result, err = New("tags", "id", db).ReplaceBatch(rows(1, 3, 5, 6, 7))
+	if err != nil || result.Rows != 5 || result.Inserted != 4 || result.Replaced != 1 {
+		t.Errorf("ReplaceBatch = %+v, %v, want 4 inserted and 1 replaced", result, err)
+	}
+	result, err = New("tags", "id", db).Replace(map[string]interface{}{"id": 1, "tag": "newer"})
+	if err != nil || result.Inserted != 0 || result.Replaced != 1 {
+		t.Errorf("Replace of an existing row = %+v, %v, want 1 replaced", result, err)
+	}
+	n, err := New("tags", "id", db).Where("tag = ?", "old").Count()
+	if err != nil || n != 0 {
+		t.Errorf("rows left unreplaced = %d, %v, want 0", n, err)
+	}
+}
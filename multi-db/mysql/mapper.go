@@ -0,0 +1,403 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+	bytesType   = reflect.TypeOf([]byte(nil))
+)
+
+// timeLayouts are tried in order when a time column is read as text (parseTime off)
+var timeLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02",
+	"15:04:05",
+}
+
+// field is a struct field mapped to a column with the builder tag
+type field struct {
+	name  string
+	index []int
+	omit  bool
+}
+
+// fieldCache holds the []field of each struct type
+var fieldCache sync.Map
+
+// builderFields returns the fields of struct type t tagged with builder:"column[,omit]"
+func builderFields(t reflect.Type) []field {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.([]field)
+	}
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("builder")
+		if tag == "" || f.PkgPath != "" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		fd := field{name: parts[0], index: f.Index}
+		for _, opt := range parts[1:] {
+			if opt == "omit" {
+				fd.omit = true
+			}
+		}
+		fields = append(fields, fd)
+	}
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+// All executes the query and scans every row into a T, a struct with builder tags.
+// Columns are scanned straight into the fields, without building Result maps.
+// Columns without a matching field are ignored, a value that does not fit its field is an error.
+func All[T any](q *Query) ([]T, error) {
+	return AllContext[T](context.Background(), q)
+}
+
+// AllContext is All bound to ctx
+func AllContext[T any](ctx context.Context, q *Query) ([]T, error) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Error scanning result: %s is not a struct", typ)
+	}
+	c, err := q.CursorContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	indexes := columnFields(typ, c.Columns())
+	var results []T
+	for c.Next() {
+		var item T
+		err = c.Scan(scanDests(reflect.ValueOf(&item).Elem(), indexes)...)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, item)
+	}
+	return results, c.Err()
+}
+
+// First executes the query with LIMIT 1 and scans the row into a T
+func First[T any](q *Query) (T, error) {
+	return FirstContext[T](context.Background(), q)
+}
+
+// FirstContext is First bound to ctx
+func FirstContext[T any](ctx context.Context, q *Query) (T, error) {
+	var item T
+	results, err := AllContext[T](ctx, q.Limit(1))
+	if err != nil {
+		return item, err
+	}
+	if len(results) == 0 {
+		return item, fmt.Errorf("%s", "No results")
+	}
+	return results[0], nil
+}
+
+// columnFields returns the index of the struct field tagged with each column, nil for a column without one
+func columnFields(t reflect.Type, cols []string) [][]int {
+	byName := map[string][]int{}
+	for _, f := range builderFields(t) {
+		byName[f.name] = f.index
+	}
+	indexes := make([][]int, len(cols))
+	for i, col := range cols {
+		indexes[i] = byName[col]
+	}
+	return indexes
+}
+
+// scanDests returns the Scan destinations of a row for the struct val: a fieldScanner for the columns
+// with a field, a discarded value for the others
+func scanDests(val reflect.Value, indexes [][]int) []interface{} {
+	dests := make([]interface{}, len(indexes))
+	for i, index := range indexes {
+		if index == nil {
+			dests[i] = new(interface{})
+			continue
+		}
+		dests[i] = fieldScanner{owner: val.Type(), index: index, dst: val.FieldByIndex(index)}
+	}
+	return dests
+}
+
+// fieldScanner scans a column straight into a struct field, converting the value with assign
+type fieldScanner struct {
+	owner reflect.Type
+	index []int
+	dst   reflect.Value
+}
+
+func (s fieldScanner) Scan(v interface{}) error {
+	err := assign(s.dst, v)
+	if err != nil {
+		sf := s.owner.FieldByIndex(s.index)
+		return fmt.Errorf("Error scanning into %s.%s (%s): %s", s.owner.Name(), sf.Name, sf.Type, err)
+	}
+	return nil
+}
+
+// ScanResult copies a result row into the struct pointed to by dest, using the builder tags
+func ScanResult(row Result, dest interface{}) error {
+	val := reflect.ValueOf(dest)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("Error scanning result: dest must be a pointer to a struct, got %T", dest)
+	}
+	val = val.Elem()
+	for _, f := range builderFields(val.Type()) {
+		v, ok := row[f.name]
+		if !ok {
+			continue
+		}
+		err := assign(val.FieldByIndex(f.index), v)
+		if err != nil {
+			sf := val.Type().FieldByIndex(f.index)
+			return fmt.Errorf("Error scanning column %s into %s.%s (%s): %s", f.name, val.Type().Name(), sf.Name, sf.Type, err)
+		}
+	}
+	return nil
+}
+
+// assign converts a value read from the database into dst
+// NULL sets the zero value, which is nil for pointers and invalid for sql.Null* types.
+func assign(dst reflect.Value, v interface{}) error {
+	if dst.CanAddr() && dst.Addr().Type().Implements(scannerType) {
+		return dst.Addr().Interface().(sql.Scanner).Scan(v)
+	}
+	if v == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	if dst.Kind() == reflect.Ptr {
+		elem := reflect.New(dst.Type().Elem())
+		err := assign(elem.Elem(), v)
+		if err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	}
+	if dst.Type() == bytesType {
+		switch b := v.(type) {
+		case []byte:
+			dst.SetBytes(append([]byte(nil), b...))
+		case string:
+			dst.SetBytes([]byte(b))
+		default:
+			return mismatch(v)
+		}
+		return nil
+	}
+	if dst.Type() == timeType {
+		t, err := toTime(v)
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(t))
+		return nil
+	}
+	if b, ok := v.([]byte); ok {
+		v = string(b)
+	}
+	src := reflect.ValueOf(v)
+	switch dst.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		var err error
+		switch src.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n = src.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if src.Uint() > 1<<63-1 {
+				return fmt.Errorf("value %d overflows", src.Uint())
+			}
+			n = int64(src.Uint())
+		case reflect.String:
+			n, err = strconv.ParseInt(src.String(), 10, 64)
+		default:
+			return mismatch(v)
+		}
+		if err != nil {
+			return err
+		}
+		if dst.OverflowInt(n) {
+			return fmt.Errorf("value %d overflows", n)
+		}
+		dst.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		var err error
+		switch src.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if src.Int() < 0 {
+				return fmt.Errorf("negative value %d", src.Int())
+			}
+			n = uint64(src.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n = src.Uint()
+		case reflect.String:
+			n, err = strconv.ParseUint(src.String(), 10, 64)
+		default:
+			return mismatch(v)
+		}
+		if err != nil {
+			return err
+		}
+		if dst.OverflowUint(n) {
+			return fmt.Errorf("value %d overflows", n)
+		}
+		dst.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		var n float64
+		var err error
+		switch src.Kind() {
+		case reflect.Float32, reflect.Float64:
+			n = src.Float()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n = float64(src.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n = float64(src.Uint())
+		case reflect.String:
+			n, err = strconv.ParseFloat(src.String(), 64)
+		default:
+			return mismatch(v)
+		}
+		if err != nil {
+			return err
+		}
+		dst.SetFloat(n)
+	case reflect.Bool:
+		switch src.Kind() {
+		case reflect.Bool:
+			dst.SetBool(src.Bool())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			dst.SetBool(src.Int() != 0)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			dst.SetBool(src.Uint() != 0)
+		case reflect.String:
+			b, err := strconv.ParseBool(src.String())
+			if err != nil {
+				return err
+			}
+			dst.SetBool(b)
+		default:
+			return mismatch(v)
+		}
+	case reflect.String:
+		switch src.Kind() {
+		case reflect.String:
+			dst.SetString(src.String())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64, reflect.Bool:
+			dst.SetString(fmt.Sprintf("%v", v))
+		default:
+			return mismatch(v)
+		}
+	default:
+		if src.Type().AssignableTo(dst.Type()) {
+			dst.Set(src)
+		} else if src.Type().ConvertibleTo(dst.Type()) {
+			dst.Set(src.Convert(dst.Type()))
+		} else {
+			return mismatch(v)
+		}
+	}
+	return nil
+}
+
+// toTime reads a DATETIME, DATE or TIMESTAMP value, given as time.Time or as text in UTC
+func toTime(v interface{}) (time.Time, error) {
+	var str string
+	switch t := v.(type) {
+	case time.Time:
+		return t, nil
+	case []byte:
+		str = string(t)
+	case string:
+		str = t
+	default:
+		return time.Time{}, mismatch(v)
+	}
+	for _, layout := range timeLayouts {
+		t, err := time.ParseInLocation(layout, str, time.UTC)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q as time", str)
+}
+
+// paramsFromObject builds insert params from the builder tags of a struct, skipping omit fields
+func paramsFromObject(object interface{}) (map[string]interface{}, error) {
+	val := reflect.Indirect(reflect.ValueOf(object))
+	if val.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Error reading object: %T is not a struct", object)
+	}
+	params := make(map[string]interface{})
+	for _, f := range builderFields(val.Type()) {
+		if f.omit {
+			continue
+		}
+		v, err := fieldValue(val.FieldByIndex(f.index))
+		if err != nil {
+			sf := val.Type().FieldByIndex(f.index)
+			return nil, fmt.Errorf("Error reading %s.%s (%s) for column %s: %s", val.Type().Name(), sf.Name, sf.Type, f.name, err)
+		}
+		params[f.name] = v
+	}
+	return params, nil
+}
+
+// fieldValue returns the value to bind for a struct field - nil pointers are NULL
+func fieldValue(v reflect.Value) (interface{}, error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, nil
+		}
+		if v.Type().Implements(valuerType) {
+			return v.Interface(), nil
+		}
+		return fieldValue(v.Elem())
+	}
+	if v.Type().Implements(valuerType) || v.Type() == timeType || v.Type() == bytesType {
+		return v.Interface(), nil
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(valuerType) {
+		return v.Addr().Interface(), nil
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return v.Bool(), nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", v.Type())
+}
+
+func mismatch(v interface{}) error {
+	return fmt.Errorf("cannot convert %T %v", v, v)
+}
@@ -0,0 +1,64 @@
+package mysql
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type mapperItem struct {
+	Id      int64      `builder:"id"`
+	Name    string     `builder:"name"`
+	Note    *string    `builder:"note"`
+	Active  bool       `builder:"active"`
+	Data    []byte     `builder:"data"`
+	Created time.Time  `builder:"created"`
+	Deleted *time.Time `builder:"deleted"`
+}
+
+func TestAllFirst(t *testing.T) {
+	db := openSQLite(t,
+		"CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT, note TEXT, active BOOLEAN, data BLOB, created DATETIME, deleted DATETIME, extra TEXT)",
+		"INSERT INTO items VALUES (1, 'a', NULL, 1, x'0102', '2024-05-06 07:08:09', NULL, 'x'), (2, 'b', 'n', 0, NULL, '2024-05-07', '2024-06-01 00:00:00', 'y')",
+	)
+	items, err := All[mapperItem](New("items", "id", db).Order("id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("All returned %d items, want 2", len(items))
+	}
+	a, b := items[0], items[1]
+	if a.Id != 1 || a.Name != "a" || a.Note != nil || !a.Active || string(a.Data) != "\x01\x02" || a.Deleted != nil {
+		t.Errorf("All first item = %+v", a)
+	}
+	if !a.Created.Equal(time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)) {
+		t.Errorf("Created = %s", a.Created)
+	}
+	if b.Note == nil || *b.Note != "n" || b.Active || b.Data != nil || b.Deleted == nil {
+		t.Errorf("All second item = %+v", b)
+	}
+
+	first, err := First[mapperItem](New("items", "id", db).Where("name = ?", "b"))
+	if err != nil || first.Id != 2 {
+		t.Errorf("First = %+v, %v, want id 2", first, err)
+	}
+	_, err = First[mapperItem](New("items", "id", db).Where("name = ?", "z"))
+	if err == nil || err.Error() != "No results" {
+		t.Errorf("First without rows error = %v, want No results", err)
+	}
+}
+
+func TestAllMismatch(t *testing.T) {
+	db := openSQLite(t,
+		"CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)",
+		"INSERT INTO items VALUES (1, 'a')",
+	)
+	type item struct {
+		Name int `builder:"name"`
+	}
+	_, err := All[item](New("items", "id", db))
+	if err == nil || !strings.Contains(err.Error(), "item.Name (int)") {
+		t.Errorf("All error = %v, want a mismatch on item.Name", err)
+	}
+}
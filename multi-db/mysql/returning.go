@@ -0,0 +1,140 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// InsertReturning inserts a record and returns cols of the inserted row (every column if none are given),
+// including the keys and defaults set by the database. The row is read with INSERT ... RETURNING where the
+// dialect has it. Otherwise (MySQL) it is selected back by its primary key, through the executor of the query:
+// a query bound with UseTx or UseXA reads it inside the transaction, a query on the pool may read it on another
+// connection of the pool. When the row cannot be found that way - the table has no auto increment key and params
+// give none - the inserted params are returned instead, without the defaults set by the database.
+func (q *Query) InsertReturning(params map[string]interface{}, cols ...string) (Result, error) {
+	return q.InsertReturningContext(context.Background(), params, cols...)
+}
+
+// InsertReturningContext is InsertReturning bound to ctx
+func (q *Query) InsertReturningContext(ctx context.Context, params map[string]interface{}, cols ...string) (Result, error) {
+	sql := q.formatInsertSQL(params)
+	args := valuesFromParams(params)
+	if Debug {
+		fmt.Printf("INSERT SQL:%s %v\n", sql, args)
+	}
+	var quoted []string
+	for _, col := range cols {
+		quoted = append(quoted, q.quote(col))
+	}
+	d := q.dialect()
+	if d.Returning() {
+		sel := "*"
+		if len(quoted) > 0 {
+			sel = strings.Join(quoted, ",")
+		}
+		rows, err := querySql(ctx, q.executor(), fmt.Sprintf("%s RETURNING %s", sql, sel), args...)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		return firstRow(rows)
+	}
+	id, err := insert(ctx, q.executor(), d, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	var key interface{} = id
+	if v, ok := params[q.primaryKey]; ok && !isZero(v) {
+		// The key was given, LastInsertId is only set for auto increment keys
+		key = v
+	} else if id == 0 {
+		return insertedParams(params, cols), nil
+	}
+	fetch := &Query{tableName: q.tableName, primaryKey: q.primaryKey, dbName: q.dbName, db: q.db, d: d}
+	if len(quoted) > 0 {
+		fetch.Select(quoted...)
+	}
+	results, err := fetch.Where(Eq(fetch.pk(), key)).Limit(1).ResultsContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading back the row inserted into %s: %s", q.tableName, err)
+	}
+	if len(results) == 0 {
+		// The insert id is not the primary key
+		return insertedParams(params, cols), nil
+	}
+	return results[0], nil
+}
+
+// insertedParams returns cols of params (all of them if none are given) as the Result of an insert
+func insertedParams(params map[string]interface{}, cols []string) Result {
+	result := Result{}
+	for k, v := range params {
+		result[k] = v
+	}
+	if len(cols) == 0 {
+		return result
+	}
+	selected := Result{}
+	for _, col := range cols {
+		if v, ok := params[col]; ok {
+			selected[col] = v
+		}
+	}
+	return selected
+}
+
+// ReturnInserted makes InsertObject read the inserted row back into the object, which must be a pointer,
+// setting its primary key and the columns filled in by the database (see InsertReturning). Fields tagged
+// omit are never written, so their column defaults apply.
+func (q *Query) ReturnInserted() *Query {
+	q.returnInserted = true
+	return q
+}
+
+// insertObjectReturning inserts params and scans the inserted row into object, returning its id
+func (q *Query) insertObjectReturning(ctx context.Context, object interface{}, params map[string]interface{}) (int64, error) {
+	val := reflect.ValueOf(object)
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		return 0, fmt.Errorf("Error inserting %T: ReturnInserted needs a pointer to the object", object)
+	}
+	var cols []string
+	for _, f := range builderFields(val.Elem().Type()) {
+		cols = append(cols, f.name)
+	}
+	row, err := q.InsertReturningContext(ctx, params, cols...)
+	if err != nil {
+		return 0, err
+	}
+	err = ScanResult(row, object)
+	if err != nil {
+		return 0, err
+	}
+	// Keys that are not integers have no insert id
+	var id int64
+	assign(reflect.ValueOf(&id).Elem(), row[q.primaryKey])
+	return id, nil
+}
+
+// firstRow scans the first row of rows
+func firstRow(rows *sql.Rows) (Result, error) {
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching columns: %s", err)
+	}
+	if !rows.Next() {
+		err = rows.Err()
+		if err != nil {
+			return nil, fmt.Errorf("Error fetching rows: %s", err)
+		}
+		return nil, fmt.Errorf("%s", "No results")
+	}
+	return ScanRow(cols, rows)
+}
+
+// isZero reports whether a bound value is NULL or the zero value of its type
+func isZero(v interface{}) bool {
+	return v == nil || reflect.ValueOf(v).IsZero()
+}
@@ -0,0 +1,60 @@
+package mysql
+
+import (
+	"testing"
+)
+
+// noReturning is SQLite without RETURNING, so inserted rows are selected back as on MySQL
+type noReturning struct {
+	Dialect
+}
+
+func (noReturning) Returning() bool {
+	return false
+}
+
+func TestInsertReturning(t *testing.T) {
+	db := openSQLite(t,
+		"CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, status TEXT DEFAULT 'new')",
+		"CREATE TABLE codes (code TEXT PRIMARY KEY DEFAULT 'x', name TEXT, status TEXT DEFAULT 'new')",
+	)
+	dialects := map[string]Dialect{"returning": SQLite, "select": noReturning{SQLite}}
+	for name, d := range dialects {
+		t.Run(name, func(t *testing.T) {
+			q := New("users", "id", db)
+			q.d = d
+			row, err := q.InsertReturning(map[string]interface{}{"name": "ann"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if row["id"] == nil || row["status"] != "new" {
+				t.Errorf("InsertReturning = %v, want the assigned id and default status", row)
+			}
+
+			// A given key that is not auto increment
+			q = New("codes", "code", db)
+			q.d = d
+			row, err = q.InsertReturning(map[string]interface{}{"code": name, "name": "ann"}, "code", "status")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(row) != 2 || row["code"] != name || row["status"] != "new" {
+				t.Errorf("InsertReturning with a key = %v, want code and default status", row)
+			}
+		})
+	}
+
+	// Without RETURNING nor a known key the row cannot be selected back, the params are returned
+	q := New("codes", "code", db)
+	q.d = noReturning{SQLite}
+	row, err := q.InsertReturning(map[string]interface{}{"name": "bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(row) != 1 || row["name"] != "bob" {
+		t.Errorf("InsertReturning without a key = %v, want the params", row)
+	}
+	if n, _ := New("codes", "code", db).Where("name = ?", "bob").Count(); n != 1 {
+		t.Errorf("inserted rows = %d, want 1", n)
+	}
+}
@@ -0,0 +1,149 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// SafeMode refuses UpdateAll and DeleteAll on a query without WHERE (or LIMIT on MySQL), unless the query calls AllowFullTable
+var SafeMode = true
+
+// FullTableError is returned in safe mode for an UPDATE or DELETE without WHERE (or LIMIT on MySQL)
+type FullTableError struct {
+	Op    string
+	Table string
+}
+
+func (e *FullTableError) Error() string {
+	return fmt.Sprintf("Refusing %s on every row of %s: add a WHERE (or LIMIT on MySQL), or call AllowFullTable()", e.Op, e.Table)
+}
+
+// TooManyRowsError is returned when an UPDATE or DELETE affects more rows than MaxAffected allows - its changes are rolled back
+type TooManyRowsError struct {
+	Op       string
+	Table    string
+	Affected int64
+	Max      int64
+}
+
+func (e *TooManyRowsError) Error() string {
+	return fmt.Sprintf("Rolled back %s on %s: %d rows affected, more than the maximum %d", e.Op, e.Table, e.Affected, e.Max)
+}
+
+// AllowFullTable lets UpdateAll and DeleteAll run without WHERE in safe mode
+func (q *Query) AllowFullTable() *Query {
+	q.allowFullTable = true
+	return q
+}
+
+// MaxAffected makes UpdateAll and DeleteAll roll back and return a TooManyRowsError when they affect more than n rows.
+// Outside a transaction the statement runs in its own transaction; inside a Tx or XA the error is
+// returned for the caller's transaction to roll back (WithTx and WithXA do so).
+func (q *Query) MaxAffected(n int64) *Query {
+	q.maxAffected = n
+	return q
+}
+
+// checkFullTable returns a FullTableError if the statement would touch every row in safe mode
+func (q *Query) checkFullTable(op string) error {
+	if !SafeMode || q.allowFullTable || q.hasPredicate() || (len(q.limit) > 0 && q.dialect().UpdateLimit()) {
+		return nil
+	}
+	return &FullTableError{Op: op, Table: q.tableName}
+}
+
+// whereFilter tracks whether where groups filter rows. Groups are joined left to right with AND and OR,
+// AND binding tighter, so the clause filters when each OR-joined term has a group other than 1=1.
+type whereFilter struct {
+	// Every term before the current one filters
+	terms bool
+	// The current term filters, leaving out the last group
+	term bool
+	// How the last group joins the ones before it, empty for the first group
+	op string
+	// The last group filters - OrWhereIn keeps it, as an IN always filters
+	last bool
+}
+
+// fold returns whether the terms before the current one filter, and whether the current one does
+func (f whereFilter) fold() (terms bool, term bool) {
+	switch f.op {
+	case "":
+		return true, f.last
+	case "OR":
+		return f.terms && f.term, f.last
+	}
+	return f.terms, f.term || f.last
+}
+
+// add joins a group with op
+func (f whereFilter) add(op string, last bool) whereFilter {
+	terms, term := f.fold()
+	return whereFilter{terms: terms, term: term, op: op, last: last}
+}
+
+// filters reports whether a where group sql can filter rows, i.e. is not 1=1
+func filters(sql string) bool {
+	sql = strings.Join(strings.Fields(sql), "")
+	for strings.HasPrefix(sql, "(") && strings.HasSuffix(sql, ")") {
+		sql = sql[1 : len(sql)-1]
+	}
+	return sql != "" && sql != "1=1"
+}
+
+// hasPredicate reports whether the where clause has a condition that can filter rows
+func (q *Query) hasPredicate() bool {
+	if q.where == "" {
+		return false
+	}
+	terms, term := q.whereFilter.fold()
+	return terms && term
+}
+
+// execGuarded executes an UPDATE or DELETE, enforcing MaxAffected
+func (q *Query) execGuarded(ctx context.Context, op string) (int64, error) {
+	if q.maxAffected <= 0 {
+		rs, err := q.ResultContext(ctx)
+		if err != nil {
+			return 0, err
+		}
+		return rs.RowsAffected()
+	}
+	db, ok := q.executor().(*sql.DB)
+	if !ok {
+		// Already in a transaction, which the caller rolls back on error
+		rs, err := q.ResultContext(ctx)
+		if err != nil {
+			return 0, err
+		}
+		return q.checkAffected(op, rs)
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	rs, err := exec(ctx, tx, q.QueryString(), q.queryArgs()...)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	affected, err := q.checkAffected(op, rs)
+	if err != nil {
+		tx.Rollback()
+		return affected, err
+	}
+	return affected, tx.Commit()
+}
+
+func (q *Query) checkAffected(op string, rs sql.Result) (int64, error) {
+	affected, err := rs.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if affected > q.maxAffected {
+		return affected, &TooManyRowsError{Op: op, Table: q.tableName, Affected: affected, Max: q.maxAffected}
+	}
+	return affected, nil
+}
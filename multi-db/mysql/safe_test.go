@@ -0,0 +1,120 @@
+package mysql
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFullTableError(t *testing.T) {
+	db := openSQLite(t,
+		"CREATE TABLE items (id INTEGER PRIMARY KEY, kind TEXT)",
+		"INSERT INTO items (id, kind) VALUES (1, 'a'), (2, 'a'), (3, 'b')",
+	)
+	queries := map[string]*Query{
+		"no where":      New("items", "id", db),
+		"empty Where()": New("items", "id", db).Where(),
+		"empty string":  New("items", "id", db).Where(""),
+		"empty And()":   New("items", "id", db).Where(And()),
+		"1=1 groups":    New("items", "id", db).Where("1=1").OrWhere(And(Raw(""))),
+		"1=1 OR id":     New("items", "id", db).Where("1=1").OrWhere("id = ?", 5),
+		"id OR 1=1":     New("items", "id", db).Where("id = ?", 5).OrWhere(" ( 1 = 1 ) "),
+		"1=1 OR id AND": New("items", "id", db).Where("1=1").OrWhere("id = ?", 5).Where("kind = ?", "a"),
+		"OrWhereIn 1=1": New("items", "id", db).Where("1=1").OrWhereIn("id", []int{1, 2}),
+	}
+	for name, q := range queries {
+		var fullTable *FullTableError
+		_, err := q.UpdateAll(map[string]interface{}{"kind": "c"})
+		if !errors.As(err, &fullTable) || fullTable.Op != "UPDATE" {
+			t.Errorf("UpdateAll with %s error = %v, want a FullTableError", name, err)
+		}
+		err = q.DeleteAll()
+		if !errors.As(err, &fullTable) || fullTable.Op != "DELETE" {
+			t.Errorf("DeleteAll with %s error = %v, want a FullTableError", name, err)
+		}
+	}
+	if n := count(t, db, "items"); n != 3 {
+		t.Fatalf("Count = %d, want 3", n)
+	}
+
+	n, err := New("items", "id", db).Where("1=1").Where("kind = ?", "a").UpdateAll(map[string]interface{}{"kind": "c"})
+	if err != nil || n != 2 {
+		t.Errorf("UpdateAll with a where = %d, %v, want 2", n, err)
+	}
+	n, err = New("items", "id", db).Where("kind = ?", "b").OrWhere("1=1").Where("id = ?", 1).UpdateAll(map[string]interface{}{"kind": "e"})
+	if err != nil || n != 2 {
+		t.Errorf("UpdateAll with filtering OR terms = %d, %v, want 2", n, err)
+	}
+	n, err = New("items", "id", db).AllowFullTable().UpdateAll(map[string]interface{}{"kind": "d"})
+	if err != nil || n != 3 {
+		t.Errorf("UpdateAll with AllowFullTable = %d, %v, want 3", n, err)
+	}
+}
+
+func TestTooManyRowsError(t *testing.T) {
+	db := openSQLite(t,
+		"CREATE TABLE items (id INTEGER PRIMARY KEY, kind TEXT)",
+		"INSERT INTO items (id, kind) VALUES (1, 'a'), (2, 'a'), (3, 'b')",
+	)
+	var tooMany *TooManyRowsError
+	_, err := New("items", "id", db).Where("kind = ?", "a").MaxAffected(1).UpdateAll(map[string]interface{}{"kind": "c"})
+	if !errors.As(err, &tooMany) || tooMany.Affected != 2 || tooMany.Max != 1 {
+		t.Errorf("UpdateAll error = %v, want a TooManyRowsError for 2 rows", err)
+	}
+	err = New("items", "id", db).Where("kind = ?", "a").MaxAffected(1).DeleteAll()
+	if !errors.As(err, &tooMany) {
+		t.Errorf("DeleteAll error = %v, want a TooManyRowsError", err)
+	}
+	// Inside a transaction the error rolls WithTx back
+	err = WithTx(db, func(tx *Tx) error {
+		return tx.New("items", "id").Where("kind = ?", "a").MaxAffected(1).DeleteAll()
+	})
+	if !errors.As(err, &tooMany) {
+		t.Errorf("WithTx error = %v, want a TooManyRowsError", err)
+	}
+	n, err := New("items", "id", db).Where("kind = ?", "a").Count()
+	if err != nil || n != 2 {
+		t.Errorf("rows of kind a = %d, %v, want 2 after the rollbacks", n, err)
+	}
+	_, err = New("items", "id", db).Where("kind = ?", "b").MaxAffected(1).UpdateAll(map[string]interface{}{"kind": "c"})
+	if err != nil {
+		t.Errorf("UpdateAll of 1 row with MaxAffected(1) error = %v", err)
+	}
+}
+
+func TestUpdateLimit(t *testing.T) {
+	db := openSQLite(t,
+		"CREATE TABLE items (id INTEGER PRIMARY KEY, kind TEXT)",
+		"INSERT INTO items (id, kind) VALUES (1, 'a'), (2, 'a'), (3, 'b')",
+	)
+	// SQLite has no LIMIT on DELETE, so it neither runs nor passes safe mode
+	queries := map[string]*Query{
+		"Limit":        New("items", "id", db).Limit(1),
+		"Where Limit":  New("items", "id", db).Where("kind = ?", "a").Limit(1),
+		"Order":        New("items", "id", db).Where("kind = ?", "a").Order("id"),
+		"Limit Offset": New("items", "id", db).Where("kind = ?", "a").Limit(1).Offset(1),
+	}
+	for name, q := range queries {
+		var fullTable *FullTableError
+		err := q.DeleteAll()
+		if err == nil || errors.As(err, &fullTable) {
+			t.Errorf("DeleteAll with %s error = %v, want an unsupported LIMIT error", name, err)
+		}
+	}
+	if n := count(t, db, "items"); n != 3 {
+		t.Fatalf("Count = %d, want 3", n)
+	}
+
+	// MySQL renders it, and counts it as limiting the rows
+	q := New("items", "id", db).Order("id").Limit(2)
+	q.d = MySQL
+	if err := q.checkFullTable("DELETE"); err != nil {
+		t.Errorf("checkFullTable with a MySQL LIMIT error = %v", err)
+	}
+	q.UpdateSql("DELETE FROM `items`")
+	if got, want := q.QueryString(), "DELETE FROM `items` ORDER BY id LIMIT 2;"; got != want {
+		t.Errorf("MySQL DELETE = %s, want %s", got, want)
+	}
+	if err := q.Offset(1).checkLimit("DELETE"); err == nil {
+		t.Errorf("checkLimit with a MySQL OFFSET error = nil")
+	}
+}
@@ -0,0 +1,96 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// UpdateObject updates the row of a struct with builder tags, found by the primary key given to New().
+// Fields tagged omit are never written, and if cols are given only those columns are updated.
+// Conditions already on q also apply, q itself is left unchanged.
+func (q *Query) UpdateObject(object interface{}, cols ...string) (int64, error) {
+	return q.UpdateObjectContext(context.Background(), object, cols...)
+}
+
+// UpdateObjectContext is UpdateObject bound to ctx
+func (q *Query) UpdateObjectContext(ctx context.Context, object interface{}, cols ...string) (int64, error) {
+	pk, err := q.objectPK(object)
+	if err != nil {
+		return 0, err
+	}
+	if pk.IsZero() {
+		return 0, fmt.Errorf("Error updating %T: primary key %s is not set", object, q.primaryKey)
+	}
+	params, err := paramsFromObject(object)
+	if err != nil {
+		return 0, err
+	}
+	delete(params, q.primaryKey)
+	if len(cols) > 0 {
+		partial := make(map[string]interface{}, len(cols))
+		for _, col := range cols {
+			v, ok := params[col]
+			if !ok {
+				return 0, fmt.Errorf("Error updating %T: no writable field for column %s", object, col)
+			}
+			partial[col] = v
+		}
+		params = partial
+	}
+	if len(params) == 0 {
+		return 0, nil
+	}
+	pkValue, err := fieldValue(pk)
+	if err != nil {
+		return 0, err
+	}
+	// Update a copy, so q can be reused for other objects
+	return q.clone().Where(Eq(q.pk(), pkValue)).UpdateAllContext(ctx, params)
+}
+
+// Save inserts a struct with builder tags if its primary key is zero, or updates its row (see UpdateObject).
+// On insert the new id is set on the primary key field when object is a pointer.
+func (q *Query) Save(object interface{}, cols ...string) error {
+	return q.SaveContext(context.Background(), object, cols...)
+}
+
+// SaveContext is Save bound to ctx
+func (q *Query) SaveContext(ctx context.Context, object interface{}, cols ...string) error {
+	pk, err := q.objectPK(object)
+	if err != nil {
+		return err
+	}
+	if !pk.IsZero() {
+		_, err = q.UpdateObjectContext(ctx, object, cols...)
+		return err
+	}
+	params, err := paramsFromObject(object)
+	if err != nil {
+		return err
+	}
+	// Let the database assign the key
+	delete(params, q.primaryKey)
+	id, err := q.InsertContext(ctx, params)
+	if err != nil {
+		return err
+	}
+	if pk.CanSet() {
+		return assign(pk, id)
+	}
+	return nil
+}
+
+// objectPK returns the primary key field of a struct with builder tags
+func (q *Query) objectPK(object interface{}) (reflect.Value, error) {
+	val := reflect.Indirect(reflect.ValueOf(object))
+	if val.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("Error reading object: %T is not a struct", object)
+	}
+	for _, f := range builderFields(val.Type()) {
+		if f.name == q.primaryKey {
+			return val.FieldByIndex(f.index), nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("Error reading %T: no field tagged builder:%q for the primary key", object, q.primaryKey)
+}
@@ -0,0 +1,57 @@
+package mysql
+
+import (
+	"testing"
+)
+
+type saveUser struct {
+	Id   int64  `builder:"id"`
+	Name string `builder:"name"`
+}
+
+func TestUpdateObjectTwice(t *testing.T) {
+	db := openSQLite(t,
+		"CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)",
+		"INSERT INTO users (name) VALUES ('ann'), ('bob')",
+	)
+	q := New("users", "id", db)
+	for _, u := range []saveUser{{1, "anna"}, {2, "bobby"}} {
+		n, err := q.UpdateObject(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 1 {
+			t.Errorf("UpdateObject(%v) affected %d rows, want 1", u, n)
+		}
+	}
+	users, err := All[saveUser](New("users", "id", db).Order("id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 || users[0].Name != "anna" || users[1].Name != "bobby" {
+		t.Errorf("users = %v, want anna and bobby", users)
+	}
+}
+
+func TestSaveTwice(t *testing.T) {
+	db := openSQLite(t, "CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)")
+	q := New("users", "id", db)
+	u := &saveUser{Name: "ann"}
+	for _, name := range []string{"ann", "anna", "annie"} {
+		u.Name = name
+		err := q.Save(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if u.Id != 1 {
+		t.Errorf("Save set id %d, want 1", u.Id)
+	}
+	got, err := First[saveUser](New("users", "id", db))
+	if err != nil || got.Name != "annie" {
+		t.Errorf("saved user = %v, %v, want annie", got, err)
+	}
+	if n := count(t, db, "users"); n != 1 {
+		t.Errorf("Count = %d, want 1", n)
+	}
+}
@@ -0,0 +1,389 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Table describes a table of a registered database, see Describe
+type Table struct {
+	Name    string
+	Columns []Column
+	// PrimaryKey lists the primary key columns in key order
+	PrimaryKey []string
+	// Indexes lists the indexes the server reports in name order, the primary key index included where it has one
+	Indexes     []Index
+	ForeignKeys []ForeignKey
+}
+
+// Column describes a table column
+type Column struct {
+	Name string
+	// Type is the declared type, e.g. int(10) unsigned or character varying(255)
+	Type string
+	// DataType is the lower-cased base type, e.g. int, varchar or timestamp without time zone
+	DataType string
+	Nullable bool
+	// Default is the default expression as the server reports it, nil if the column has none
+	Default       *string
+	AutoIncrement bool
+}
+
+// Index describes an index over one or more columns
+type Index struct {
+	Name    string
+	Columns []string
+	Unique  bool
+	Primary bool
+}
+
+// ForeignKey describes a foreign key, Columns and RefColumns pair up in order
+type ForeignKey struct {
+	Name       string
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+	OnUpdate   string
+	OnDelete   string
+}
+
+// Column returns the column named name, nil if the table has none
+func (t *Table) Column(name string) *Column {
+	for i := range t.Columns {
+		if t.Columns[i].Name == name {
+			return &t.Columns[i]
+		}
+	}
+	return nil
+}
+
+// UniqueKeys returns the unique indexes other than the primary key
+func (t *Table) UniqueKeys() []Index {
+	var keys []Index
+	for _, idx := range t.Indexes {
+		if idx.Unique && !idx.Primary {
+			keys = append(keys, idx)
+		}
+	}
+	return keys
+}
+
+// Tables returns the base table names of the registered database dbName in sorted order - the tables of the
+// connected schema on MySQL and PostgreSQL
+func Tables(dbName string) ([]string, error) {
+	return TablesContext(context.Background(), dbName)
+}
+
+// TablesContext is Tables bound to ctx
+func TablesContext(ctx context.Context, dbName string) ([]string, error) {
+	c, err := Lookup(dbName)
+	if err != nil {
+		return nil, err
+	}
+	query := ""
+	switch c.Dialect.Name() {
+	case "mysql":
+		query = "SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE() AND table_type = 'BASE TABLE' ORDER BY table_name"
+	case "postgres":
+		query = "SELECT table_name FROM information_schema.tables WHERE table_schema = current_schema() AND table_type = 'BASE TABLE' ORDER BY table_name"
+	case "sqlite":
+		query = "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name"
+	default:
+		return nil, fmt.Errorf("Schema introspection is not supported on %s (%s)", c.Name, c.Driver)
+	}
+	var tables []string
+	err = schemaRows(ctx, c, query, nil, func(rows *sql.Rows) error {
+		var name string
+		err := rows.Scan(&name)
+		tables = append(tables, name)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
+
+// Describe returns the columns, keys, indexes and foreign keys of table in the registered database dbName
+func Describe(dbName string, table string) (*Table, error) {
+	return DescribeContext(context.Background(), dbName, table)
+}
+
+// DescribeContext is Describe bound to ctx
+func DescribeContext(ctx context.Context, dbName string, table string) (*Table, error) {
+	c, err := Lookup(dbName)
+	if err != nil {
+		return nil, err
+	}
+	t := &Table{Name: table}
+	switch c.Dialect.Name() {
+	case "mysql":
+		err = describeMySQL(ctx, c, t)
+	case "postgres":
+		err = describePostgres(ctx, c, t)
+	case "sqlite":
+		err = describeSQLite(ctx, c, t)
+	default:
+		return nil, fmt.Errorf("Schema introspection is not supported on %s (%s)", c.Name, c.Driver)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error describing %s.%s: %s", c.Name, table, err)
+	}
+	if len(t.Columns) == 0 {
+		return nil, fmt.Errorf("Error describing %s.%s: no such table", c.Name, table)
+	}
+	return t, nil
+}
+
+func describeMySQL(ctx context.Context, c *Connection, t *Table) error {
+	err := schemaRows(ctx, c, `SELECT column_name, column_type, data_type, is_nullable, column_default, extra
+		FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? ORDER BY ordinal_position`,
+		[]interface{}{t.Name}, func(rows *sql.Rows) error {
+			var col Column
+			var nullable, extra string
+			var def sql.NullString
+			err := rows.Scan(&col.Name, &col.Type, &col.DataType, &nullable, &def, &extra)
+			if err != nil {
+				return err
+			}
+			col.DataType = strings.ToLower(col.DataType)
+			col.Nullable = nullable == "YES"
+			col.Default = nullString(def)
+			col.AutoIncrement = strings.Contains(strings.ToLower(extra), "auto_increment")
+			t.Columns = append(t.Columns, col)
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+	err = schemaRows(ctx, c, `SELECT index_name, non_unique, column_name
+		FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = ? ORDER BY index_name, seq_in_index`,
+		[]interface{}{t.Name}, func(rows *sql.Rows) error {
+			var name string
+			var nonUnique int
+			var col sql.NullString
+			err := rows.Scan(&name, &nonUnique, &col)
+			// Functional key parts have no column
+			if err == nil && col.Valid {
+				t.addIndexColumn(name, col.String, nonUnique == 0, name == "PRIMARY")
+			}
+			return err
+		})
+	if err != nil {
+		return err
+	}
+	return schemaRows(ctx, c, `SELECT k.constraint_name, k.column_name, k.referenced_table_name, k.referenced_column_name, r.update_rule, r.delete_rule
+		FROM information_schema.key_column_usage k
+		JOIN information_schema.referential_constraints r
+			ON r.constraint_schema = k.constraint_schema AND r.constraint_name = k.constraint_name AND r.table_name = k.table_name
+		WHERE k.table_schema = DATABASE() AND k.table_name = ? AND k.referenced_table_name IS NOT NULL
+		ORDER BY k.constraint_name, k.ordinal_position`,
+		[]interface{}{t.Name}, t.scanForeignKey)
+}
+
+func describePostgres(ctx context.Context, c *Connection, t *Table) error {
+	err := schemaRows(ctx, c, `SELECT column_name, data_type, character_maximum_length, is_nullable, column_default, is_identity
+		FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = $1 ORDER BY ordinal_position`,
+		[]interface{}{t.Name}, func(rows *sql.Rows) error {
+			var col Column
+			var nullable, identity string
+			var length sql.NullInt64
+			var def sql.NullString
+			err := rows.Scan(&col.Name, &col.DataType, &length, &nullable, &def, &identity)
+			if err != nil {
+				return err
+			}
+			col.Type = col.DataType
+			if length.Valid {
+				col.Type = fmt.Sprintf("%s(%d)", col.DataType, length.Int64)
+			}
+			col.Nullable = nullable == "YES"
+			col.Default = nullString(def)
+			// serial columns default to their sequence
+			col.AutoIncrement = identity == "YES" || strings.HasPrefix(def.String, "nextval(")
+			t.Columns = append(t.Columns, col)
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+	// information_schema has no indexes, read them from the catalog
+	err = schemaRows(ctx, c, `SELECT i.relname, ix.indisunique, ix.indisprimary, a.attname
+		FROM pg_class t
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		JOIN pg_index ix ON ix.indrelid = t.oid
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		JOIN LATERAL unnest(ix.indkey) WITH ORDINALITY AS k(attnum, ord) ON true
+		JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+		WHERE n.nspname = current_schema() AND t.relname = $1
+		ORDER BY i.relname, k.ord`,
+		[]interface{}{t.Name}, func(rows *sql.Rows) error {
+			var name, col string
+			var unique, primary bool
+			err := rows.Scan(&name, &unique, &primary, &col)
+			if err != nil {
+				return err
+			}
+			t.addIndexColumn(name, col, unique, primary)
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+	return schemaRows(ctx, c, `SELECT k.constraint_name, k.column_name, ref.table_name, ref.column_name, r.update_rule, r.delete_rule
+		FROM information_schema.referential_constraints r
+		JOIN information_schema.key_column_usage k
+			ON k.constraint_schema = r.constraint_schema AND k.constraint_name = r.constraint_name
+		JOIN information_schema.key_column_usage ref
+			ON ref.constraint_schema = r.unique_constraint_schema AND ref.constraint_name = r.unique_constraint_name
+			AND ref.ordinal_position = k.position_in_unique_constraint
+		WHERE k.table_schema = current_schema() AND k.table_name = $1
+		ORDER BY k.constraint_name, k.ordinal_position`,
+		[]interface{}{t.Name}, t.scanForeignKey)
+}
+
+func describeSQLite(ctx context.Context, c *Connection, t *Table) error {
+	var pk []string
+	err := schemaRows(ctx, c, `SELECT name, type, "notnull", dflt_value, pk FROM pragma_table_info(?) ORDER BY cid`,
+		[]interface{}{t.Name}, func(rows *sql.Rows) error {
+			var col Column
+			var notNull, key int
+			var def sql.NullString
+			err := rows.Scan(&col.Name, &col.Type, &notNull, &def, &key)
+			if err != nil {
+				return err
+			}
+			col.DataType = strings.ToLower(col.Type)
+			if i := strings.Index(col.DataType, "("); i >= 0 {
+				col.DataType = strings.TrimSpace(col.DataType[:i])
+			}
+			col.Nullable = notNull == 0 && key == 0
+			col.Default = nullString(def)
+			t.Columns = append(t.Columns, col)
+			if key > 0 {
+				for len(pk) < key {
+					pk = append(pk, "")
+				}
+				pk[key-1] = col.Name
+			}
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+	// An INTEGER PRIMARY KEY is the rowid, assigned on insert
+	if len(pk) == 1 {
+		col := t.Column(pk[0])
+		if col.DataType == "integer" {
+			col.AutoIncrement = true
+		}
+	}
+	var indexes []Index
+	err = schemaRows(ctx, c, `SELECT name, "unique", origin FROM pragma_index_list(?) ORDER BY name`,
+		[]interface{}{t.Name}, func(rows *sql.Rows) error {
+			var idx Index
+			var origin string
+			err := rows.Scan(&idx.Name, &idx.Unique, &origin)
+			idx.Primary = origin == "pk"
+			indexes = append(indexes, idx)
+			return err
+		})
+	if err != nil {
+		return err
+	}
+	for _, idx := range indexes {
+		err = schemaRows(ctx, c, `SELECT name FROM pragma_index_info(?) ORDER BY seqno`,
+			[]interface{}{idx.Name}, func(rows *sql.Rows) error {
+				var col sql.NullString
+				err := rows.Scan(&col)
+				// Expression columns have no name
+				if col.Valid {
+					t.addIndexColumn(idx.Name, col.String, idx.Unique, idx.Primary)
+				}
+				return err
+			})
+		if err != nil {
+			return err
+		}
+	}
+	t.PrimaryKey = pk
+	// Foreign keys are unnamed, rows of one key share its id
+	fkID := -1
+	return schemaRows(ctx, c, `SELECT id, "table", "from", "to", on_update, on_delete FROM pragma_foreign_key_list(?) ORDER BY id, seq`,
+		[]interface{}{t.Name}, func(rows *sql.Rows) error {
+			var id int
+			var refTable, col, onUpdate, onDelete string
+			var ref sql.NullString
+			err := rows.Scan(&id, &refTable, &col, &ref, &onUpdate, &onDelete)
+			if err != nil {
+				return err
+			}
+			if id != fkID {
+				fkID = id
+				t.ForeignKeys = append(t.ForeignKeys, ForeignKey{RefTable: refTable, OnUpdate: onUpdate, OnDelete: onDelete})
+			}
+			fk := &t.ForeignKeys[len(t.ForeignKeys)-1]
+			fk.Columns = append(fk.Columns, col)
+			// to is NULL when the key references the primary key, left empty here
+			fk.RefColumns = append(fk.RefColumns, ref.String)
+			return nil
+		})
+}
+
+// addIndexColumn appends col to the index name, adding the index on its first column.
+// Rows must come grouped by index, in column order.
+func (t *Table) addIndexColumn(name string, col string, unique bool, primary bool) {
+	n := len(t.Indexes)
+	if n == 0 || t.Indexes[n-1].Name != name {
+		t.Indexes = append(t.Indexes, Index{Name: name, Unique: unique, Primary: primary})
+		n++
+	}
+	t.Indexes[n-1].Columns = append(t.Indexes[n-1].Columns, col)
+	if primary {
+		t.PrimaryKey = t.Indexes[n-1].Columns
+	}
+}
+
+// scanForeignKey reads a foreign key column row: name, column, referenced table and column, update and delete rules.
+// Rows must come grouped by key, in column order.
+func (t *Table) scanForeignKey(rows *sql.Rows) error {
+	var name, col, refTable, refCol, onUpdate, onDelete string
+	err := rows.Scan(&name, &col, &refTable, &refCol, &onUpdate, &onDelete)
+	if err != nil {
+		return err
+	}
+	n := len(t.ForeignKeys)
+	if n == 0 || t.ForeignKeys[n-1].Name != name {
+		t.ForeignKeys = append(t.ForeignKeys, ForeignKey{Name: name, RefTable: refTable, OnUpdate: onUpdate, OnDelete: onDelete})
+		n++
+	}
+	fk := &t.ForeignKeys[n-1]
+	fk.Columns = append(fk.Columns, col)
+	fk.RefColumns = append(fk.RefColumns, refCol)
+	return nil
+}
+
+// schemaRows runs an introspection query on the connection's pool, calling fn for each row
+func schemaRows(ctx context.Context, c *Connection, query string, args []interface{}, fn func(rows *sql.Rows) error) error {
+	rows, err := querySql(ctx, c.DB, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		err = fn(rows)
+		if err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func nullString(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
@@ -0,0 +1,100 @@
+package mysql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDescribeSQLite(t *testing.T) {
+	db := openSQLite(t,
+		"CREATE TABLE groups (id INTEGER PRIMARY KEY, name TEXT NOT NULL DEFAULT 'none')",
+		`CREATE TABLE memberships (
+			group_id INTEGER NOT NULL REFERENCES groups (id) ON DELETE CASCADE,
+			user_id INTEGER NOT NULL,
+			role VARCHAR(20) DEFAULT 'member',
+			code TEXT,
+			PRIMARY KEY (user_id, group_id),
+			UNIQUE (code)
+		)`,
+		"CREATE INDEX memberships_role ON memberships (role, user_id)",
+	)
+	tables, err := Tables(db)
+	if err != nil || !reflect.DeepEqual(tables, []string{"groups", "memberships"}) {
+		t.Errorf("Tables = %v, %v, want groups and memberships", tables, err)
+	}
+
+	table, err := Describe(db, "memberships")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(table.PrimaryKey, []string{"user_id", "group_id"}) {
+		t.Errorf("PrimaryKey = %v, want [user_id group_id]", table.PrimaryKey)
+	}
+	var names []string
+	for _, col := range table.Columns {
+		names = append(names, col.Name)
+		if col.AutoIncrement {
+			t.Errorf("column %s is AutoIncrement in a composite key", col.Name)
+		}
+	}
+	if !reflect.DeepEqual(names, []string{"group_id", "user_id", "role", "code"}) {
+		t.Errorf("Columns = %v, want them in table order", names)
+	}
+	role := table.Column("role")
+	if role == nil || role.Type != "VARCHAR(20)" || role.DataType != "varchar" || !role.Nullable ||
+		role.Default == nil || *role.Default != "'member'" {
+		t.Errorf("role column = %+v, want a nullable varchar defaulting to 'member'", role)
+	}
+	if col := table.Column("group_id"); col == nil || col.Nullable || col.Default != nil {
+		t.Errorf("group_id column = %+v, want NOT NULL without a default", col)
+	}
+
+	var primary, unique, plain []Index
+	for _, idx := range table.Indexes {
+		switch {
+		case idx.Primary:
+			primary = append(primary, idx)
+		case idx.Unique:
+			unique = append(unique, idx)
+		default:
+			plain = append(plain, idx)
+		}
+	}
+	if len(primary) != 1 || !primary[0].Unique || !reflect.DeepEqual(primary[0].Columns, []string{"user_id", "group_id"}) {
+		t.Errorf("primary key indexes = %+v, want one unique index on user_id, group_id", primary)
+	}
+	if len(unique) != 1 || !reflect.DeepEqual(unique[0].Columns, []string{"code"}) {
+		t.Errorf("unique indexes = %+v, want one on code", unique)
+	}
+	if !reflect.DeepEqual(table.UniqueKeys(), unique) {
+		t.Errorf("UniqueKeys = %+v, want %+v", table.UniqueKeys(), unique)
+	}
+	if len(plain) != 1 || plain[0].Name != "memberships_role" || !reflect.DeepEqual(plain[0].Columns, []string{"role", "user_id"}) {
+		t.Errorf("other indexes = %+v, want memberships_role on role, user_id", plain)
+	}
+
+	want := []ForeignKey{{
+		Columns:    []string{"group_id"},
+		RefTable:   "groups",
+		RefColumns: []string{"id"},
+		OnUpdate:   "NO ACTION",
+		OnDelete:   "CASCADE",
+	}}
+	if !reflect.DeepEqual(table.ForeignKeys, want) {
+		t.Errorf("ForeignKeys = %+v, want %+v", table.ForeignKeys, want)
+	}
+
+	groups, err := Describe(db, "groups")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := groups.Column("id"); !reflect.DeepEqual(groups.PrimaryKey, []string{"id"}) || id == nil || !id.AutoIncrement {
+		t.Errorf("groups key = %v, id column %+v, want an AutoIncrement id", groups.PrimaryKey, id)
+	}
+	if len(groups.ForeignKeys) != 0 || len(groups.UniqueKeys()) != 0 {
+		t.Errorf("groups keys = %+v, %+v, want none", groups.ForeignKeys, groups.UniqueKeys())
+	}
+	if _, err := Describe(db, "missing"); err == nil {
+		t.Errorf("Describe of a missing table error = nil")
+	}
+}
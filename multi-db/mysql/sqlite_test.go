@@ -0,0 +1,63 @@
+package mysql
+
+import (
+	"testing"
+)
+
+type sqliteTag struct {
+	Id   int64  `builder:"id"`
+	AdId int    `builder:"ad_id"`
+	Tag  string `builder:"tag"`
+}
+
+func TestSQLiteQueries(t *testing.T) {
+	db := openSQLite(t, "CREATE TABLE tags (id INTEGER PRIMARY KEY AUTOINCREMENT, ad_id INTEGER, tag TEXT)")
+
+	// A zero primary key is left for SQLite to assign
+	for i, tag := range []sqliteTag{{AdId: 1, Tag: "sport"}, {AdId: 1, Tag: "news"}, {AdId: 2, Tag: "sport"}} {
+		id, err := New("tags", "id", db).InsertObject(tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != int64(i+1) {
+			t.Errorf("InsertObject id = %d, want %d", id, i+1)
+		}
+	}
+
+	results, err := New("tags", "id", db).Where("tag = ?", "sport").Order("id").Results()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0]["id"] != int64(1) || results[1]["ad_id"] != int64(2) {
+		t.Errorf("Results = %v, want the two sport tags", results)
+	}
+	n, err := New("tags", "id", db).Where("ad_id = ?", 1).Count()
+	if err != nil || n != 2 {
+		t.Errorf("Count = %d, %v, want 2", n, err)
+	}
+	rows, err := New("tags", "id", db).Order("id").Offset(1).Results()
+	if err != nil || len(rows) != 2 || rows[0]["id"] != int64(2) {
+		t.Errorf("Results with Offset = %v, %v, want ids 2 and 3", rows, err)
+	}
+
+	n, err = New("tags", "id", db).Where("tag = ?", "sport").UpdateAll(map[string]interface{}{"tag": "sports"})
+	if err != nil || n != 2 {
+		t.Errorf("UpdateAll = %d, %v, want 2", n, err)
+	}
+	if n, _ := New("tags", "id", db).Where("tag = ?", "sports").Count(); n != 2 {
+		t.Errorf("updated rows = %d, want 2", n)
+	}
+
+	err = New("tags", "id", db).Where("ad_id = ?", 2).DeleteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags, err := All[sqliteTag](New("tags", "id", db).Order("id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []sqliteTag{{1, 1, "sports"}, {2, 1, "news"}}
+	if len(tags) != 2 || tags[0] != want[0] || tags[1] != want[1] {
+		t.Errorf("tags = %v, want %v", tags, want)
+	}
+}
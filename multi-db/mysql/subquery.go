@@ -0,0 +1,37 @@
+package mysql
+
+import "fmt"
+
+// WhereExists adds a WHERE EXISTS (subquery) clause
+func (q *Query) WhereExists(sub *Query) *Query {
+	return q.Where(Exists(sub))
+}
+
+// WhereNotExists adds a WHERE NOT EXISTS (subquery) clause
+func (q *Query) WhereNotExists(sub *Query) *Query {
+	return q.Where(NotExists(sub))
+}
+
+// FromSub selects from a subquery: FROM (SELECT ...) AS alias
+func (q *Query) FromSub(sub *Query, alias string) *Query {
+	sql, args := sub.subquery()
+	q.from = fmt.Sprintf("(%s) AS %s", sql, q.quote(alias))
+	q.fromArgs = args
+	q.fromAlias = alias
+	q.reset()
+	return q
+}
+
+// SelectSub adds a scalar subquery to the selected columns: (SELECT ...) AS alias
+func (q *Query) SelectSub(sub *Query, alias string) *Query {
+	sql, args := sub.subquery()
+	q.sel = append(q.sel, fmt.Sprintf("(%s) AS %s", sql, q.quote(alias)))
+	q.selArgs = append(q.selArgs, args...)
+	q.reset()
+	return q
+}
+
+// subquery returns the sql of the query for embedding in another one, with its args in order
+func (q *Query) subquery() (string, []interface{}) {
+	return q.buildSQL(), q.queryArgs()
+}
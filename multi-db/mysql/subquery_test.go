@@ -0,0 +1,62 @@
+package mysql
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// inline replaces each ? of sql with the next arg, to check args line up with their placeholders
+func inline(sql string, args []interface{}) string {
+	for _, arg := range args {
+		sql = strings.Replace(sql, "?", fmt.Sprintf("'%v'", arg), 1)
+	}
+	return sql
+}
+
+// argsQuery has a subquery or args in every clause, each arg naming its clause unless values are given
+func argsQuery(db string, values ...interface{}) *Query {
+	if len(values) == 0 {
+		values = []interface{}{"with", "select", "from", "where", "in", "having", "union"}
+	}
+	return New("items", "id", db).
+		With("cheap", New("items", "id", db).Select("id").Where("price < ?", values[0])).
+		Select("kind").
+		SelectSub(New("items", "id", db).Select("COUNT(*)").Where("kind = ?", values[1]), "n").
+		FromSub(New("items", "id", db).Where("kind <> ?", values[2]), "s").
+		Where("price > ?", values[3]).
+		WhereIn("id", New("cheap", "id", db).Select("id").Where("id <> ?", values[4])).
+		Group("kind").
+		Having("COUNT(*) > ?", values[5]).
+		Union(New("items", "id", db).Select("kind", "price").Where("kind = ?", values[6])).
+		Order("kind")
+}
+
+func TestSubqueryArgsOrder(t *testing.T) {
+	db := openSQLite(t,
+		"CREATE TABLE items (id INTEGER PRIMARY KEY, kind TEXT, price INTEGER)",
+		"INSERT INTO items (id, kind, price) VALUES (1, 'a', 5), (2, 'a', 20), (3, 'b', 7), (4, 'c', 30)",
+	)
+	q := argsQuery(db)
+	want := `WITH cheap AS (SELECT id FROM "items" WHERE (price < 'with')) ` +
+		`SELECT kind,(SELECT COUNT(*) FROM "items" WHERE (kind = 'select')) AS "n" ` +
+		`FROM (SELECT "items".* FROM "items" WHERE (kind <> 'from')) AS "s" ` +
+		`WHERE (price > 'where') AND (id IN (SELECT id FROM "cheap" WHERE (id <> 'in'))) ` +
+		`GROUP BY kind HAVING COUNT(*) > 'having' ` +
+		`UNION SELECT kind,price FROM "items" WHERE (kind = 'union') ORDER BY kind;`
+	if got := inline(q.QueryString(), q.queryArgs()); got != want {
+		t.Errorf("query with inlined args =\n%s\nwant\n%s", got, want)
+	}
+
+	rows, err := argsQuery(db, 10, "a", "c", 6, 1, 0, "c").Results()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, row := range rows {
+		got = append(got, fmt.Sprintf("%v:%v", row["kind"], row["n"]))
+	}
+	if strings.Join(got, " ") != "b:2 c:30" {
+		t.Errorf("Results = %v, want b:2 c:30", got)
+	}
+}
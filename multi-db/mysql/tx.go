@@ -0,0 +1,104 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Tx is a transaction on one registered database, queries bound to it with UseTx run inside the transaction
+type Tx struct {
+	dbName string
+	tx     *sql.Tx
+}
+
+// Begin starts a transaction on the registered database dbName
+func Begin(dbName string) (*Tx, error) {
+	return BeginTx(context.Background(), dbName, nil)
+}
+
+// BeginTx starts a transaction on the registered database dbName - the transaction is rolled back if ctx is cancelled
+func BeginTx(ctx context.Context, dbName string, opts *sql.TxOptions) (*Tx, error) {
+	c, err := Lookup(dbName)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := c.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Error starting transaction on %s: %s", c.Name, err)
+	}
+	return &Tx{dbName: c.Name, tx: tx}, nil
+}
+
+// WithTx runs fn inside a transaction on dbName, committing if fn returns nil and rolling back on error or panic
+func WithTx(dbName string, fn func(tx *Tx) error) error {
+	return WithTxContext(context.Background(), dbName, nil, fn)
+}
+
+// WithTxContext is WithTx bound to ctx
+func WithTxContext(ctx context.Context, dbName string, opts *sql.TxOptions, fn func(tx *Tx) error) (err error) {
+	tx, err := BeginTx(ctx, dbName, opts)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+	return fn(tx)
+}
+
+// Database returns the registered database name of the transaction
+func (t *Tx) Database() string {
+	return t.dbName
+}
+
+// Commit commits the transaction
+func (t *Tx) Commit() error {
+	return t.tx.Commit()
+}
+
+// Rollback aborts the transaction
+func (t *Tx) Rollback() error {
+	return t.tx.Rollback()
+}
+
+// New builds a new Query on table running inside the transaction
+func (t *Tx) New(table string, pk string) *Query {
+	return &Query{
+		tableName:  table,
+		primaryKey: pk,
+		dbName:     t.dbName,
+		db:         t.tx,
+	}
+}
+
+// QuerySql runs a raw select inside the transaction - NB caller must call use defer rows.Close() with rows returned
+func (t *Tx) QuerySql(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return querySql(ctx, t.tx, query, args...)
+}
+
+// Exec runs a raw statement inside the transaction
+func (t *Tx) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return exec(ctx, t.tx, query, args...)
+}
+
+// UseTx binds the query to tx, so it runs inside the transaction.
+// It panics if tx is nil or on another database than the query, as the query would silently run outside it.
+func (q *Query) UseTx(tx *Tx) *Query {
+	if tx == nil {
+		panic(fmt.Sprintf("UseTx on %s.%s with a nil transaction", q.dbName, q.tableName))
+	}
+	if tx.dbName != q.dbName {
+		panic(fmt.Sprintf("UseTx on %s.%s with a transaction on %s", q.dbName, q.tableName, tx.dbName))
+	}
+	q.db = tx.tx
+	return q
+}
@@ -0,0 +1,75 @@
+package mysql
+
+import (
+	"testing"
+)
+
+func TestWithTxSelectAndInsert(t *testing.T) {
+	db := openSQLite(t,
+		"CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)",
+		"INSERT INTO users (name) VALUES ('ann'), ('bob')",
+	)
+	err := WithTx(db, func(tx *Tx) error {
+		results, err := tx.New("users", "id").Order("id").Results()
+		if err != nil {
+			return err
+		}
+		if len(results) != 2 || results[1]["name"] != "bob" {
+			t.Errorf("Results in tx = %v, want ann and bob", results)
+		}
+		id, err := tx.New("users", "id").Insert(map[string]interface{}{"name": "cy"})
+		if err != nil {
+			return err
+		}
+		if id != 3 {
+			t.Errorf("Insert in tx id = %d, want 3", id)
+		}
+		n, err := tx.New("users", "id").Count()
+		if err != nil {
+			return err
+		}
+		if n != 3 {
+			t.Errorf("Count in tx = %d, want 3", n)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := count(t, db, "users"); n != 3 {
+		t.Errorf("Count after commit = %d, want 3", n)
+	}
+}
+
+func TestWithTxRollback(t *testing.T) {
+	db := openSQLite(t, "CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)")
+	err := WithTx(db, func(tx *Tx) error {
+		_, err := tx.New("users", "id").Insert(map[string]interface{}{"name": "ann"})
+		if err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if err != errRollback {
+		t.Fatalf("WithTx error = %v, want %v", err, errRollback)
+	}
+	if n := count(t, db, "users"); n != 0 {
+		t.Errorf("Count after rollback = %d, want 0", n)
+	}
+}
+
+func TestUseTxOtherDatabase(t *testing.T) {
+	db := openSQLite(t, "CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)")
+	other := openSQLite(t)
+	tx, err := Begin(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	defer func() {
+		if recover() == nil {
+			t.Error("UseTx with a transaction on another database did not panic")
+		}
+	}()
+	New("users", "id", db).UseTx(tx)
+}
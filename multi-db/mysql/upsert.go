@@ -0,0 +1,72 @@
+package mysql
+
+import (
+	"context"
+)
+
+// upsertAlias names the inserted row in upserts on MySQL8
+const upsertAlias = "new"
+
+// Upsert inserts a record, or updates updateCols of the existing row when it hits a duplicate key.
+// With no updateCols every column but the primary key is updated.
+// Affected counts 1 for an inserted row and 2 for an updated one (0 if the row was unchanged) on MySQL,
+// on PostgreSQL and SQLite it counts 1 for either.
+func (q *Query) Upsert(params map[string]interface{}, updateCols ...string) (WriteResult, error) {
+	return q.UpsertContext(context.Background(), params, updateCols...)
+}
+
+// UpsertContext is Upsert bound to ctx
+func (q *Query) UpsertContext(ctx context.Context, params map[string]interface{}, updateCols ...string) (WriteResult, error) {
+	return q.UpsertBatchContext(ctx, []map[string]interface{}{params}, updateCols...)
+}
+
+// UpsertObject upserts a struct with builder tags, see Upsert
+func (q *Query) UpsertObject(object interface{}, updateCols ...string) (WriteResult, error) {
+	return q.UpsertObjectContext(context.Background(), object, updateCols...)
+}
+
+// UpsertObjectContext is UpsertObject bound to ctx
+func (q *Query) UpsertObjectContext(ctx context.Context, object interface{}, updateCols ...string) (WriteResult, error) {
+	params, err := paramsFromObject(object)
+	if err != nil {
+		return WriteResult{}, err
+	}
+	return q.UpsertContext(ctx, params, updateCols...)
+}
+
+// UpsertBatch upserts rows with multi-row statements, split as InsertBatch, see Upsert
+func (q *Query) UpsertBatch(rows []map[string]interface{}, updateCols ...string) (WriteResult, error) {
+	return q.UpsertBatchContext(context.Background(), rows, updateCols...)
+}
+
+// UpsertBatchContext is UpsertBatch bound to ctx
+func (q *Query) UpsertBatchContext(ctx context.Context, rows []map[string]interface{}, updateCols ...string) (WriteResult, error) {
+	return q.writeBatch(ctx, rows, ModeUpsert, updateCols)
+}
+
+// UpsertObjects upserts a slice of structs with multi-row statements, see Upsert
+func (q *Query) UpsertObjects(objects interface{}, updateCols ...string) (WriteResult, error) {
+	return q.UpsertObjectsContext(context.Background(), objects, updateCols...)
+}
+
+// UpsertObjectsContext is UpsertObjects bound to ctx
+func (q *Query) UpsertObjectsContext(ctx context.Context, objects interface{}, updateCols ...string) (WriteResult, error) {
+	rows, err := paramsFromObjects(objects)
+	if err != nil {
+		return WriteResult{}, err
+	}
+	return q.UpsertBatchContext(ctx, rows, updateCols...)
+}
+
+// upsertColumns returns the columns an upsert updates - updateCols, or every inserted column but the primary key
+func (q *Query) upsertColumns(cols []string, updateCols []string) []string {
+	if len(updateCols) > 0 {
+		return updateCols
+	}
+	for _, col := range cols {
+		if col != q.primaryKey {
+			updateCols = append(updateCols, col)
+		}
+	}
+	return updateCols
+}
@@ -0,0 +1,87 @@
+package mysql
+
+import (
+	"testing"
+)
+
+func TestUpsert(t *testing.T) {
+	db := openSQLite(t,
+		"CREATE TABLE tags (id INTEGER PRIMARY KEY, ad_id INTEGER, tag TEXT)",
+		"INSERT INTO tags (id, ad_id, tag) VALUES (1, 1, 'a')",
+	)
+	result, err := New("tags", "id", db).Upsert(map[string]interface{}{"id": 1, "ad_id": 2, "tag": "b"})
+	if err != nil || result.Affected != 1 {
+		t.Errorf("Upsert of an existing row = %+v, %v", result, err)
+	}
+	result, err = New("tags", "id", db).Upsert(map[string]interface{}{"id": 2, "ad_id": 2, "tag": "c"})
+	if err != nil || result.Affected != 1 {
+		t.Errorf("Upsert of a new row = %+v, %v", result, err)
+	}
+
+	// Only tag is updated
+	result, err = New("tags", "id", db).UpsertBatch([]map[string]interface{}{
+		{"id": 1, "ad_id": 9, "tag": "d"},
+		{"id": 3, "ad_id": 3, "tag": "e"},
+	}, "tag")
+	if err != nil || result.Rows != 2 {
+		t.Errorf("UpsertBatch = %+v, %v", result, err)
+	}
+
+	type tag struct {
+		Id   int64  `builder:"id"`
+		AdId int    `builder:"ad_id"`
+		Tag  string `builder:"tag"`
+	}
+	_, err = New("tags", "id", db).UpsertObject(tag{Id: 2, AdId: 7, Tag: "f"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags, err := All[tag](New("tags", "id", db).Order("id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []tag{{1, 2, "d"}, {2, 7, "f"}, {3, 3, "e"}}
+	if len(tags) != len(want) {
+		t.Fatalf("tags = %v, want %v", tags, want)
+	}
+	for i := range want {
+		if tags[i] != want[i] {
+			t.Errorf("tags[%d] = %v, want %v", i, tags[i], want[i])
+		}
+	}
+}
+
+func TestUpsertRowAlias(t *testing.T) {
+	cols := []string{"id", "tag"}
+	if got, want := MySQL.OnConflict(ModeUpsert, "id", cols, []string{"tag"}), " ON DUPLICATE KEY UPDATE `tag`=VALUES(`tag`)"; got != want {
+		t.Errorf("MySQL upsert = %q, want %q", got, want)
+	}
+	if got, want := MySQL8.OnConflict(ModeUpsert, "id", cols, []string{"tag"}), " AS new ON DUPLICATE KEY UPDATE `tag`=new.`tag`"; got != want {
+		t.Errorf("MySQL8 upsert = %q, want %q", got, want)
+	}
+
+	// Connections choose the syntax one by one
+	err := ConnectionConfig{Name: "mysql57", Host: "127.0.0.1"}.Register()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Close("mysql57")
+	err = ConnectionConfig{Name: "mysql80", Host: "127.0.0.1", UpsertRowAlias: true}.Register()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Close("mysql80")
+	for name, want := range map[string]Dialect{"mysql57": MySQL, "mysql80": MySQL8} {
+		c, err := Lookup(name)
+		if err != nil || c.Dialect != want {
+			t.Errorf("dialect of %s = %v, %v, want %v", name, c.Dialect, err, want)
+		}
+	}
+	err = ConnectionConfig{Name: "lite", Driver: "sqlite3", UpsertRowAlias: true}.Register()
+	if err == nil {
+		t.Error("upsert_row_alias on sqlite returned no error")
+	}
+	if _, err = Lookup("lite"); err == nil {
+		t.Error("connection with a bad upsert_row_alias was registered")
+	}
+}
@@ -0,0 +1,216 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// XA is a unit of work spanning several registered databases, committed all-or-nothing with
+// MySQL XA two-phase commit. Each database gets one branch pinned to a single connection,
+// queries bound to it with UseXA run inside that branch.
+type XA struct {
+	gtrid    string
+	branches map[string]*xaBranch
+	order    []string
+}
+
+type xaBranch struct {
+	dbName string
+	bqual  string
+	conn   *sql.Conn
+	state  int
+}
+
+// Branch states
+const (
+	xaActive = iota
+	xaIdle
+	xaPrepared
+	xaDone
+)
+
+var xaSeq uint64
+
+// WithXA runs fn inside an XA transaction with one branch on each database in dbNames.
+// If fn returns nil every branch is prepared and then committed; if fn fails, panics or any
+// branch fails to prepare, every branch is rolled back.
+//
+// A failure during the commit phase leaves the remaining branches prepared on their servers,
+// the returned error names them so they can be resolved with XA RECOVER / XA COMMIT.
+func WithXA(ctx context.Context, dbNames []string, fn func(x *XA) error) (err error) {
+	x := &XA{
+		gtrid:    fmt.Sprintf("multidb-%d-%d", time.Now().UnixNano(), atomic.AddUint64(&xaSeq, 1)),
+		branches: make(map[string]*xaBranch),
+	}
+	defer x.close()
+	for _, name := range dbNames {
+		err = x.start(ctx, name)
+		if err != nil {
+			x.rollback(ctx)
+			return err
+		}
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			x.rollback(ctx)
+			panic(p)
+		}
+	}()
+	err = fn(x)
+	if err != nil {
+		x.rollback(ctx)
+		return err
+	}
+	return x.commit(ctx)
+}
+
+// New builds a new Query on table running inside the branch for dbName
+func (x *XA) New(table string, pk string, dbName string) *Query {
+	q := &Query{
+		tableName:  table,
+		primaryKey: pk,
+		dbName:     dbName,
+	}
+	return q.UseXA(x)
+}
+
+// Exec runs a raw statement inside the branch for dbName
+func (x *XA) Exec(ctx context.Context, dbName string, query string, args ...interface{}) (sql.Result, error) {
+	b, ok := x.branches[dbName]
+	if !ok {
+		return nil, fmt.Errorf("Database %s is not part of XA %s", dbName, x.gtrid)
+	}
+	return exec(ctx, b.conn, query, args...)
+}
+
+// UseXA binds the query to the XA branch for its database.
+// It panics if x is nil or has no branch on the query's database, as the query would silently run outside it.
+func (q *Query) UseXA(x *XA) *Query {
+	if x == nil {
+		panic(fmt.Sprintf("UseXA on %s.%s with a nil XA", q.dbName, q.tableName))
+	}
+	b, ok := x.branches[q.dbName]
+	if !ok {
+		panic(fmt.Sprintf("UseXA on %s.%s: database %s is not part of XA %s", q.dbName, q.tableName, q.dbName, x.gtrid))
+	}
+	q.db = b.conn
+	return q
+}
+
+// start pins a connection to dbName and opens its branch
+func (x *XA) start(ctx context.Context, dbName string) error {
+	c, err := Lookup(dbName)
+	if err != nil {
+		return err
+	}
+	if c.Dialect.Name() != "mysql" {
+		return fmt.Errorf("XA is not supported on %s (%s)", c.Name, c.Driver)
+	}
+	if _, ok := x.branches[c.Name]; ok {
+		return nil
+	}
+	conn, err := c.DB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("Error connecting to %s: %s", c.Name, err)
+	}
+	b := &xaBranch{dbName: c.Name, bqual: fmt.Sprintf("%d", len(x.order)+1), conn: conn, state: xaDone}
+	x.branches[c.Name] = b
+	x.order = append(x.order, c.Name)
+	err = x.statement(ctx, b, "XA START")
+	if err != nil {
+		return err
+	}
+	b.state = xaActive
+	return nil
+}
+
+// commit ends and prepares every branch, then commits them
+func (x *XA) commit(ctx context.Context) error {
+	for _, name := range x.order {
+		b := x.branches[name]
+		err := x.statement(ctx, b, "XA END")
+		if err == nil {
+			b.state = xaIdle
+			err = x.statement(ctx, b, "XA PREPARE")
+		}
+		if err != nil {
+			x.rollback(ctx)
+			return err
+		}
+		b.state = xaPrepared
+	}
+	var failed []string
+	var first error
+	for _, name := range x.order {
+		b := x.branches[name]
+		err := x.statement(ctx, b, "XA COMMIT")
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%s)", name, x.xid(b)))
+			if first == nil {
+				first = err
+			}
+			continue
+		}
+		b.state = xaDone
+	}
+	if first != nil {
+		return fmt.Errorf("Error committing XA %s: %s - branches left prepared: %s", x.gtrid, first, strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// rollback aborts every branch that is still open
+func (x *XA) rollback(ctx context.Context) {
+	// Rollback must run even if ctx is what failed
+	ctx = context.Background()
+	for _, name := range x.order {
+		b := x.branches[name]
+		if b.state == xaActive {
+			if x.statement(ctx, b, "XA END") == nil {
+				b.state = xaIdle
+			}
+		}
+		if b.state == xaIdle || b.state == xaPrepared {
+			err := x.statement(ctx, b, "XA ROLLBACK")
+			if err != nil {
+				fmt.Println("ERROR XA ROLLBACK "+name+": ", err.Error())
+				continue
+			}
+			b.state = xaDone
+		}
+	}
+}
+
+// close returns the pinned connections to their pools, discarding any left inside a branch
+func (x *XA) close() {
+	for _, name := range x.order {
+		b := x.branches[name]
+		if b.state != xaDone {
+			b.conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		}
+		b.conn.Close()
+	}
+}
+
+// statement runs an XA statement for the branch - XA statements are sent unprepared
+func (x *XA) statement(ctx context.Context, b *xaBranch, stmt string) error {
+	query := fmt.Sprintf("%s %s", stmt, x.xid(b))
+	if Debug {
+		fmt.Println("XA:", b.dbName, query)
+	}
+	_, err := b.conn.ExecContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("Error running %s on %s: %s", query, b.dbName, err)
+	}
+	return nil
+}
+
+// xid formats the branch identifier as 'gtrid','bqual' - branches on the same server need distinct bquals
+func (x *XA) xid(b *xaBranch) string {
+	return fmt.Sprintf("'%s','%s'", x.gtrid, b.bqual)
+}
@@ -0,0 +1,16 @@
+package mysql
+
+import (
+	"testing"
+)
+
+func TestUseXAOtherDatabase(t *testing.T) {
+	db := openSQLite(t)
+	x := &XA{gtrid: "test", branches: map[string]*xaBranch{"other": {dbName: "other"}}}
+	defer func() {
+		if recover() == nil {
+			t.Error("UseXA without a branch on the query's database did not panic")
+		}
+	}()
+	New("users", "id", db).UseXA(x)
+}